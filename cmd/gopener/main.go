@@ -1,23 +1,35 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jimbo/gopener/internal/cli"
 	"github.com/jimbo/gopener/internal/config"
 	"github.com/jimbo/gopener/internal/launcher"
 	"github.com/jimbo/gopener/internal/tui"
 )
 
 func main() {
+	l := launcher.New()
+
+	// Any argument selects a headless subcommand instead of the TUI.
+	if len(os.Args) > 1 {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr, l)
+		stop()
+		os.Exit(code)
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "gopener: failed to load config: %v\n", err)
 		os.Exit(1)
 	}
 
-	l := launcher.New()
 	app := tui.NewApp(cfg, l)
 
 	p := tea.NewProgram(app, tea.WithAltScreen())
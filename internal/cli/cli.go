@@ -0,0 +1,334 @@
+// Package cli implements gopener's headless subcommands. They share the
+// config, scanner and launcher code with the TUI so scripts see exactly the
+// same behaviour as pressing keys in the interface.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/launcher"
+	"github.com/jimbo/gopener/internal/scanner"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK    = 0 // command succeeded
+	ExitError = 1 // command failed at runtime
+	ExitUsage = 2 // bad arguments or unknown command
+)
+
+const usage = `usage: gopener [command]
+
+Without a command gopener starts the interactive UI.
+
+commands:
+  start [--dry-run]              launch every enabled directory
+  list                           print directories, enabled state and profiles
+  profiles                       print the configured profiles
+  launch <dir> [--profile label] launch one directory (repeat --profile to pick several)
+                                 and --dry-run to only print the commands
+  help                           show this message
+
+Every command except help accepts --json to print a versioned JSON document
+instead of plain text.
+`
+
+// errUsage marks errors caused by bad arguments rather than runtime failures.
+var errUsage = errors.New("usage")
+
+// Run executes the subcommand in args (without the program name) and returns
+// the process exit code. Cancelling ctx stops a launch between items.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer, l launcher.Launcher) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return ExitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "start":
+		err = runStart(ctx, args[1:], stdout, l)
+	case "list":
+		err = runList(args[1:], stdout)
+	case "profiles":
+		err = runProfiles(args[1:], stdout)
+	case "launch":
+		err = runLaunch(ctx, args[1:], stdout, l)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return ExitOK
+	default:
+		err = fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "gopener: %v\n\n%s", err, usage)
+		return ExitUsage
+	default:
+		fmt.Fprintf(stderr, "gopener: %v\n", err)
+		return ExitError
+	}
+}
+
+// loadConfig loads the saved config and merges it with the current contents
+// of the source directory, the same way the TUI does on startup. The merged
+// result is not saved so read-only commands stay free of side effects.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.SrcDir != "" {
+		dirs, err := scanner.Scan(cfg.SrcDir, cfg.Directories)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", cfg.SrcDir, err)
+		}
+		cfg.Directories = dirs
+	}
+	return cfg, nil
+}
+
+func runStart(ctx context.Context, args []string, stdout io.Writer, l launcher.Launcher) error {
+	fs := newFlagSet("start")
+	asJSON := fs.Bool("json", false, "print the launch result as JSON")
+	dryRun := fs.Bool("dry-run", false, "print the commands instead of running them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: start takes no arguments", errUsage)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return launch(ctx, stdout, l, *asJSON, *dryRun, cfg.Directories, cfg)
+}
+
+func runList(args []string, stdout io.Writer) error {
+	fs := newFlagSet("list")
+	asJSON := fs.Bool("json", false, "print directories as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: list takes no arguments", errUsage)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out := ListJSON{Version: JSONVersion, SrcDir: cfg.SrcDir, Directories: []DirJSON{}}
+		for _, d := range cfg.Directories {
+			out.Directories = append(out.Directories, newDirJSON(cfg, d))
+		}
+		return writeJSON(stdout, out)
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	for _, d := range cfg.Directories {
+		check := "[ ]"
+		if d.Enabled {
+			check = "[x]"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", check, d.Name, strings.Join(profileLabels(cfg, d), ", "))
+	}
+	return tw.Flush()
+}
+
+func runProfiles(args []string, stdout io.Writer) error {
+	fs := newFlagSet("profiles")
+	asJSON := fs.Bool("json", false, "print profiles as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: profiles takes no arguments", errUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if *asJSON {
+		out := ProfilesJSON{Version: JSONVersion, Profiles: []ProfileJSON{}}
+		for _, p := range cfg.Profiles {
+			out.Profiles = append(out.Profiles, newProfileJSON(p))
+		}
+		return writeJSON(stdout, out)
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	for _, p := range cfg.Profiles {
+		fmt.Fprintf(tw, "%s\t%s\n", p.Label, p.Cmd)
+	}
+	return tw.Flush()
+}
+
+func runLaunch(ctx context.Context, args []string, stdout io.Writer, l launcher.Launcher) error {
+	fs := newFlagSet("launch")
+	var labels stringList
+	fs.Var(&labels, "profile", "profile `label` to launch (repeatable)")
+	asJSON := fs.Bool("json", false, "print the launch result as JSON")
+	dryRun := fs.Bool("dry-run", false, "print the commands instead of running them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: launch takes exactly one directory", errUsage)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dir := findDir(cfg, fs.Arg(0))
+	if dir == nil {
+		return fmt.Errorf("unknown directory %q", fs.Arg(0))
+	}
+
+	target := *dir
+	target.Enabled = true
+	if len(labels) > 0 {
+		target.ProfileIDs = nil
+		for _, label := range labels {
+			p := findProfileByLabel(cfg, label)
+			if p == nil {
+				return fmt.Errorf("unknown profile %q", label)
+			}
+			target.ProfileIDs = append(target.ProfileIDs, p.ID)
+		}
+	}
+	if len(target.ProfileIDs) == 0 {
+		return fmt.Errorf("%s has no profiles assigned; pass --profile", target.Name)
+	}
+
+	return launch(ctx, stdout, l, *asJSON, *dryRun, []config.DirConfig{target}, cfg)
+}
+
+// launch runs dirs through l, or through a dry-run launcher when dryRun is
+// set, and prints one line per result or a JSON document. It returns an error
+// carrying the summary when any item failed so the exit code reflects it.
+func launch(ctx context.Context, stdout io.Writer, l launcher.Launcher, asJSON, dryRun bool, dirs []config.DirConfig, cfg *config.Config) error {
+	var dr *launcher.DryRun
+	if dryRun {
+		dr = &launcher.DryRun{}
+		if !asJSON {
+			dr.Out = stdout
+		}
+		l = dr
+	}
+
+	results := l.Launch(ctx, launcher.Request{Config: cfg, Dirs: dirs})
+	if asJSON {
+		out := newLaunchJSON(results)
+		if dr != nil {
+			out.DryRun = true
+			out.Commands = []CommandJSON{}
+			for _, c := range dr.Commands {
+				out.Commands = append(out.Commands, newCommandJSON(c))
+			}
+		}
+		if err := writeJSON(stdout, out); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			// Dry runs already printed the planned commands.
+			if r.Status != launcher.StatusPlanned {
+				fmt.Fprintln(stdout, r)
+			}
+		}
+	}
+
+	if launcher.Failed(results) {
+		return errors.New(launcher.Summarize(results))
+	}
+	return nil
+}
+
+// findDir matches a directory by name, by absolute path, or by a path
+// relative to the working directory.
+func findDir(cfg *config.Config, arg string) *config.DirConfig {
+	for i := range cfg.Directories {
+		if cfg.Directories[i].Name == arg {
+			return &cfg.Directories[i]
+		}
+	}
+	if abs, err := filepath.Abs(arg); err == nil {
+		return cfg.FindDir(abs)
+	}
+	return nil
+}
+
+// findProfileByLabel returns the first profile whose label matches,
+// ignoring case.
+func findProfileByLabel(cfg *config.Config, label string) *config.Profile {
+	for i := range cfg.Profiles {
+		if strings.EqualFold(cfg.Profiles[i].Label, label) {
+			return &cfg.Profiles[i]
+		}
+	}
+	return nil
+}
+
+func profileLabels(cfg *config.Config, d config.DirConfig) []string {
+	var labels []string
+	for _, pid := range d.ProfileIDs {
+		if p := cfg.FindProfile(pid); p != nil {
+			labels = append(labels, p.Label)
+		}
+	}
+	return labels
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses args allowing flags and positional arguments to be mixed,
+// so both "launch web --profile Claude" and "launch --profile Claude web" work.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return fs.Parse(append([]string{"--"}, positional...))
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/launcher"
+)
+
+// recordingLauncher satisfies launcher.Launcher and records its arguments.
+type recordingLauncher struct {
+	called   bool
+	dirs     []config.DirConfig
+	terminal string
+	results  []launcher.Result
+}
+
+func (r *recordingLauncher) Launch(ctx context.Context, req launcher.Request) []launcher.Result {
+	r.called = true
+	r.dirs = req.Dirs
+	r.terminal = req.Config.Terminal
+	return r.results
+}
+
+// failing returns results with one launched and one failed item.
+func failing() []launcher.Result {
+	api := config.DirConfig{Name: "api", Path: "/src/api"}
+	return []launcher.Result{
+		{Dir: api, ProfileID: "p1", Profile: config.Profile{ID: "p1", Label: "Claude"}, Status: launcher.StatusLaunched},
+		{Dir: api, ProfileID: "p2", Profile: config.Profile{ID: "p2", Label: "Shell"}, Status: launcher.StatusFailed, Err: errors.New("kitty not found")},
+	}
+}
+
+// setup writes a config with two directories under a fresh src dir.
+func setup(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	src := t.TempDir()
+	for _, name := range []string{"alpha", "beta"} {
+		if err := os.Mkdir(filepath.Join(src, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{
+		SrcDir:   src,
+		Terminal: "xterm",
+		Profiles: []config.Profile{
+			{ID: "p1", Label: "Claude", Cmd: "claude"},
+			{ID: "p2", Label: "Shell", Cmd: "bash"},
+		},
+		Directories: []config.DirConfig{
+			{Path: filepath.Join(src, "alpha"), Name: "alpha", Enabled: false},
+			{Path: filepath.Join(src, "beta"), Name: "beta", Enabled: true, ProfileIDs: []string{"p1", "p2"}},
+		},
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func run(l *recordingLauncher, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, &stdout, &stderr, l)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestNoArgsIsUsage(t *testing.T) {
+	code, _, stderr := run(&recordingLauncher{})
+	if code != ExitUsage {
+		t.Errorf("exit code: got %d, want %d", code, ExitUsage)
+	}
+	if !strings.Contains(stderr, "usage:") {
+		t.Errorf("expected usage on stderr, got %q", stderr)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	code, _, _ := run(&recordingLauncher{}, "frobnicate")
+	if code != ExitUsage {
+		t.Errorf("exit code: got %d, want %d", code, ExitUsage)
+	}
+}
+
+func TestList(t *testing.T) {
+	setup(t)
+	code, stdout, stderr := run(&recordingLauncher{}, "list")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", stdout)
+	}
+	if !strings.HasPrefix(lines[0], "[ ]") || !strings.Contains(lines[0], "alpha") {
+		t.Errorf("line 0: got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[x]") || !strings.Contains(lines[1], "Claude, Shell") {
+		t.Errorf("line 1: got %q", lines[1])
+	}
+}
+
+func TestStart(t *testing.T) {
+	setup(t)
+	l := &recordingLauncher{}
+	code, _, stderr := run(l, "start")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if !l.called {
+		t.Fatal("launcher was not called")
+	}
+	if len(l.dirs) != 2 || l.terminal != "xterm" {
+		t.Errorf("unexpected launch args: dirs=%+v terminal=%q", l.dirs, l.terminal)
+	}
+}
+
+func TestStartLaunchError(t *testing.T) {
+	setup(t)
+	l := &recordingLauncher{results: failing()}
+	code, stdout, stderr := run(l, "start")
+	if code != ExitError {
+		t.Errorf("exit code: got %d, want %d", code, ExitError)
+	}
+	if !strings.Contains(stdout, "launched api → Claude") || !strings.Contains(stdout, "failed api → Shell: kitty not found") {
+		t.Errorf("stdout: got %q", stdout)
+	}
+	if !strings.Contains(stderr, "1 launched, 1 failed: kitty not found for api") {
+		t.Errorf("stderr: got %q", stderr)
+	}
+}
+
+func TestLaunchWithProfile(t *testing.T) {
+	setup(t)
+	l := &recordingLauncher{}
+	code, _, stderr := run(l, "launch", "alpha", "--profile", "shell")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if len(l.dirs) != 1 {
+		t.Fatalf("expected 1 dir, got %+v", l.dirs)
+	}
+	d := l.dirs[0]
+	if d.Name != "alpha" || !d.Enabled {
+		t.Errorf("unexpected dir: %+v", d)
+	}
+	if len(d.ProfileIDs) != 1 || d.ProfileIDs[0] != "p2" {
+		t.Errorf("ProfileIDs: got %v, want [p2]", d.ProfileIDs)
+	}
+}
+
+func TestLaunchByPathUsesAssignedProfiles(t *testing.T) {
+	src := setup(t)
+	l := &recordingLauncher{}
+	code, _, stderr := run(l, "launch", filepath.Join(src, "beta"))
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if len(l.dirs) != 1 || len(l.dirs[0].ProfileIDs) != 2 {
+		t.Errorf("unexpected dirs: %+v", l.dirs)
+	}
+}
+
+func TestLaunchErrors(t *testing.T) {
+	setup(t)
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"missing dir", []string{"launch"}, ExitUsage},
+		{"unknown dir", []string{"launch", "gamma"}, ExitError},
+		{"unknown profile", []string{"launch", "alpha", "--profile", "nope"}, ExitError},
+		{"no profiles", []string{"launch", "alpha"}, ExitError},
+		{"bad flag", []string{"launch", "alpha", "--bogus"}, ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &recordingLauncher{}
+			code, _, _ := run(l, tt.args...)
+			if code != tt.want {
+				t.Errorf("exit code: got %d, want %d", code, tt.want)
+			}
+			if l.called {
+				t.Error("launcher should not be called")
+			}
+		})
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	setup(t)
+	code, stdout, stderr := run(&recordingLauncher{}, "list", "--json")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	var out ListJSON
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if out.Version != JSONVersion {
+		t.Errorf("version: got %d, want %d", out.Version, JSONVersion)
+	}
+	if len(out.Directories) != 2 {
+		t.Fatalf("expected 2 directories, got %+v", out.Directories)
+	}
+	alpha, beta := out.Directories[0], out.Directories[1]
+	if alpha.Enabled || alpha.ProfileIDs == nil || alpha.Profiles == nil {
+		t.Errorf("alpha: got %+v (empty lists must encode as [])", alpha)
+	}
+	if !beta.Enabled || strings.Join(beta.Profiles, ",") != "Claude,Shell" {
+		t.Errorf("beta: got %+v", beta)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &cfg.Directories[1]
+	d.Terminal, d.Activate, d.Env = "kitty", config.ActivateDirenv, map[string]string{"DB_PASSWORD": "hunter2"}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	_, stdout, _ = run(&recordingLauncher{}, "list", "--json")
+	if strings.Contains(stdout, "hunter2") {
+		t.Errorf("a value was printed: %s", stdout)
+	}
+	out = ListJSON{}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if beta := out.Directories[1]; beta.Terminal != "kitty" || beta.Activate != "direnv" || !reflect.DeepEqual(beta.Env, []string{"DB_PASSWORD"}) {
+		t.Errorf("beta: got %+v", beta)
+	}
+}
+
+func TestProfilesJSON(t *testing.T) {
+	setup(t)
+	code, stdout, stderr := run(&recordingLauncher{}, "profiles", "--json")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	var out ProfilesJSON
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if out.Version != JSONVersion || len(out.Profiles) != 2 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if want := (ProfileJSON{ID: "p1", Label: "Claude", Cmd: "claude", Kind: config.KindTerminal}); !reflect.DeepEqual(out.Profiles[0], want) {
+		t.Errorf("profile 0: got %+v", out.Profiles[0])
+	}
+
+	// Environments are listed by name, so no value or secret reference is
+	// ever printed.
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Profiles[1].Kind, cfg.Profiles[1].Terminal = config.KindGUI, "kitty"
+	cfg.Profiles[1].Env = map[string]string{"TOKEN": "cmd:pass show api", "DEBUG": "1"}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	_, stdout, _ = run(&recordingLauncher{}, "profiles", "--json")
+	if strings.Contains(stdout, "pass show") {
+		t.Errorf("a value was printed: %s", stdout)
+	}
+	out = ProfilesJSON{}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	want := ProfileJSON{ID: "p2", Label: "Shell", Cmd: "bash", Kind: config.KindGUI, Terminal: "kitty", Env: []string{"DEBUG", "TOKEN"}}
+	if !reflect.DeepEqual(out.Profiles[1], want) {
+		t.Errorf("profile 1: got %+v", out.Profiles[1])
+	}
+}
+
+func TestStartJSON(t *testing.T) {
+	setup(t)
+	l := &recordingLauncher{results: failing()}
+	code, stdout, _ := run(l, "start", "--json")
+	if code != ExitError {
+		t.Errorf("exit code: got %d, want %d", code, ExitError)
+	}
+	var out LaunchJSON
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if out.Version != JSONVersion || out.OK || out.Error != out.Summary {
+		t.Errorf("unexpected output: %+v", out)
+	}
+	if len(out.Results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", out.Results)
+	}
+	want := ResultJSON{Dir: "api", Path: "/src/api", ProfileID: "p2", Profile: "Shell", Status: "failed", Error: "kitty not found"}
+	if out.Results[1] != want {
+		t.Errorf("result 1: got %+v, want %+v", out.Results[1], want)
+	}
+}
+
+func TestStartDryRunJSON(t *testing.T) {
+	setup(t)
+	l := &recordingLauncher{}
+	code, stdout, stderr := run(l, "start", "--dry-run", "--json")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if l.called {
+		t.Error("real launcher should not be called in dry run")
+	}
+	var out LaunchJSON
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if !out.OK || !out.DryRun || len(out.Commands) != 2 || len(out.Results) != 2 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if out.Results[0].Status != "planned" {
+		t.Errorf("result 0 status: got %q, want planned", out.Results[0].Status)
+	}
+	c := out.Commands[0]
+	if c.Dir != "beta" || c.Profile != "Claude" || c.Terminal != "xterm" || len(c.Argv) == 0 {
+		t.Errorf("command 0: got %+v", c)
+	}
+}
+
+func TestLaunchDryRunPrints(t *testing.T) {
+	setup(t)
+	l := &recordingLauncher{}
+	code, stdout, stderr := run(l, "launch", "beta", "--profile", "Claude", "--dry-run")
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if l.called {
+		t.Error("real launcher should not be called in dry run")
+	}
+	if !strings.Contains(stdout, "# beta → Claude") {
+		t.Errorf("stdout: got %q", stdout)
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/launcher"
+)
+
+// JSONVersion is the schema version stamped on every --json document.
+// It is bumped whenever a field is removed or changes meaning; adding
+// fields does not change it.
+const JSONVersion = 1
+
+// DirJSON describes one directory in `gopener list --json`.
+type DirJSON struct {
+	Path       string   `json:"path"`               // absolute path of the directory
+	Name       string   `json:"name"`               // directory base name
+	Enabled    bool     `json:"enabled"`            // whether `start` launches it
+	ProfileIDs []string `json:"profile_ids"`        // assigned profile IDs, in order
+	Profiles   []string `json:"profiles"`           // labels of the assigned profiles that exist
+	Terminal   string   `json:"terminal,omitempty"` // terminal override for the directory
+	Env        []string `json:"env,omitempty"`      // names of the variables it sets, never their values
+	Activate   string   `json:"activate,omitempty"` // how its environment is loaded, e.g. "direnv"
+}
+
+// ProfileJSON describes one profile in `gopener profiles --json`.
+type ProfileJSON struct {
+	ID       string   `json:"id"`                 // stable identifier referenced by profile_ids
+	Label    string   `json:"label"`              // display name, accepted by `launch --profile`
+	Cmd      string   `json:"cmd"`                // command run in the directory
+	Kind     string   `json:"kind"`               // "terminal" or "gui"
+	Terminal string   `json:"terminal,omitempty"` // terminal override for the profile
+	Env      []string `json:"env,omitempty"`      // names of the variables it sets, never their values
+}
+
+// ListJSON is the document printed by `gopener list --json`.
+type ListJSON struct {
+	Version     int       `json:"version"`
+	SrcDir      string    `json:"src_dir"`
+	Directories []DirJSON `json:"directories"`
+}
+
+// ProfilesJSON is the document printed by `gopener profiles --json`.
+type ProfilesJSON struct {
+	Version  int           `json:"version"`
+	Profiles []ProfileJSON `json:"profiles"`
+}
+
+// CommandJSON describes one command resolved by a dry run.
+type CommandJSON struct {
+	Dir       string   `json:"dir"`        // directory name
+	Path      string   `json:"path"`       // directory path
+	ProfileID string   `json:"profile_id"` // profile being launched
+	Profile   string   `json:"profile"`    // profile label
+	Terminal  string   `json:"terminal"`   // resolved terminal emulator
+	Argv      []string `json:"argv"`       // exact argv that would be executed
+}
+
+// ResultJSON describes the outcome of one (directory, profile) pair.
+type ResultJSON struct {
+	Dir       string `json:"dir"`              // directory name, empty for whole-launch failures
+	Path      string `json:"path"`             // directory path
+	ProfileID string `json:"profile_id"`       // profile ID as assigned to the directory
+	Profile   string `json:"profile"`          // profile label, empty when the ID is dangling
+	Status    string `json:"status"`           // "launched", "skipped", "failed" or "planned"
+	Reason    string `json:"reason,omitempty"` // why the item was skipped
+	Error     string `json:"error,omitempty"`  // why the item failed
+}
+
+// LaunchJSON is the document printed by `gopener start --json` and
+// `gopener launch --json`.
+type LaunchJSON struct {
+	Version  int           `json:"version"`
+	OK       bool          `json:"ok"`                 // true when no item failed
+	Error    string        `json:"error,omitempty"`    // failure summary when OK is false
+	Summary  string        `json:"summary"`            // one-line summary, e.g. "3 launched, 1 skipped"
+	Results  []ResultJSON  `json:"results"`            // one entry per (directory, profile)
+	DryRun   bool          `json:"dry_run,omitempty"`  // true when nothing was started
+	Commands []CommandJSON `json:"commands,omitempty"` // resolved commands, dry runs only
+}
+
+func newDirJSON(cfg *config.Config, d config.DirConfig) DirJSON {
+	ids := d.ProfileIDs
+	if ids == nil {
+		ids = []string{}
+	}
+	labels := profileLabels(cfg, d)
+	if labels == nil {
+		labels = []string{}
+	}
+	return DirJSON{
+		Path:       d.Path,
+		Name:       d.Name,
+		Enabled:    d.Enabled,
+		ProfileIDs: ids,
+		Profiles:   labels,
+		Terminal:   d.Terminal,
+		Env:        config.EnvNames(d.Env),
+		Activate:   d.Activate,
+	}
+}
+
+func newProfileJSON(p config.Profile) ProfileJSON {
+	kind := config.KindTerminal
+	if p.IsGUI() {
+		kind = config.KindGUI
+	}
+	return ProfileJSON{
+		ID:       p.ID,
+		Label:    p.Label,
+		Cmd:      p.Cmd,
+		Kind:     kind,
+		Terminal: p.Terminal,
+		Env:      config.EnvNames(p.Env),
+	}
+}
+
+func newCommandJSON(c launcher.Command) CommandJSON {
+	return CommandJSON{
+		Dir:       c.Dir.Name,
+		Path:      c.Dir.Path,
+		ProfileID: c.Profile.ID,
+		Profile:   c.Profile.Label,
+		Terminal:  c.Terminal,
+		Argv:      c.Argv,
+	}
+}
+
+func newResultJSON(r launcher.Result) ResultJSON {
+	out := ResultJSON{
+		Dir:       r.Dir.Name,
+		Path:      r.Dir.Path,
+		ProfileID: r.ProfileID,
+		Profile:   r.Profile.Label,
+		Status:    r.Status.String(),
+		Reason:    r.Reason,
+	}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return out
+}
+
+func newLaunchJSON(results []launcher.Result) LaunchJSON {
+	out := LaunchJSON{
+		Version: JSONVersion,
+		OK:      !launcher.Failed(results),
+		Summary: launcher.Summarize(results),
+		Results: []ResultJSON{},
+	}
+	if !out.OK {
+		out.Error = out.Summary
+	}
+	for _, r := range results {
+		out.Results = append(out.Results, newResultJSON(r))
+	}
+	return out
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
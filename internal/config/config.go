@@ -7,23 +7,210 @@ import (
 )
 
 type Profile struct {
-	ID    string `json:"id"`
-	Label string `json:"label"`
-	Cmd   string `json:"cmd"`
+	ID       string            `json:"id"`
+	Label    string            `json:"label"`
+	Cmd      string            `json:"cmd"`                // Shell command, with placeholders filled in per directory
+	Kind     string            `json:"kind,omitempty"`     // KindTerminal (default) or KindGUI
+	Shell    *Shell            `json:"shell,omitempty"`    // Overrides Config.Shell for this profile
+	OnExit   string            `json:"on_exit,omitempty"`  // What the window does when Cmd exits, default DefaultExitPolicy
+	Terminal string            `json:"terminal,omitempty"` // Overrides Config.Terminal for this profile
+	Title    string            `json:"title,omitempty"`    // Window title template, default DefaultTitle
+	Env      map[string]string `json:"env,omitempty"`      // Variables set for Cmd, see Config.EnvFor and IsSecret
+	Wrappers []string          `json:"wrappers,omitempty"` // Names of the Config.Wrappers Cmd runs through
+
+	// Wrap is the argv the shell running Cmd is started through: the
+	// profile's wrappers, then the directory's activation such as
+	// "direnv exec <dir>". The launcher resolves it for each launch; it is
+	// never saved.
+	Wrap []string `json:"-"`
+
+	// Secrets are the variables the launcher moves out of Env: secret
+	// references from the configuration, by reference, and the values of
+	// the directory's .env file, as DotenvSecret. EnvFile is the private
+	// file a launch writes their values to, for the shell running Cmd to
+	// source and remove; plans name a placeholder. Neither is saved.
+	Secrets map[string]string `json:"-"`
+	EnvFile string            `json:"-"`
+}
+
+// DefaultTitle is the window title template of profiles that set none. In a
+// template {name} is replaced by the directory name, {path} by its path and
+// {profile} by the profile label.
+const DefaultTitle = "{name} · {profile}"
+
+// TitleTemplate returns p's window title template, DefaultTitle when none
+// is set.
+func (p Profile) TitleTemplate() string {
+	if p.Title == "" {
+		return DefaultTitle
+	}
+	return p.Title
+}
+
+// Profile kinds.
+const (
+	KindTerminal = "terminal" // runs in a terminal window
+	KindGUI      = "gui"      // starts a graphical application on its own, without a terminal
+)
+
+// IsGUI reports whether p starts a graphical application rather than
+// running in a terminal.
+func (p Profile) IsGUI() bool {
+	return p.Kind == KindGUI
+}
+
+// Exit policies for Profile.OnExit.
+const (
+	ExitClose = "close" // close the window
+	ExitHold  = "hold"  // keep it open showing the exit status until a key is pressed
+	ExitShell = "shell" // drop into an interactive shell in the directory
+)
+
+// ExitPolicies lists the exit policies in the order the profile editor
+// cycles through them.
+var ExitPolicies = []string{ExitClose, ExitHold, ExitShell}
+
+// ExitPolicy returns p's exit policy, DefaultExitPolicy when none is set.
+func (p Profile) ExitPolicy() string {
+	if p.OnExit == "" {
+		return DefaultExitPolicy
+	}
+	return p.OnExit
 }
 
 type DirConfig struct {
-	Path       string   `json:"path"`
-	Name       string   `json:"name"`
-	Enabled    bool     `json:"enabled"`
-	ProfileIDs []string `json:"profile_ids"`
+	Path       string            `json:"path"`
+	Name       string            `json:"name"`
+	Enabled    bool              `json:"enabled"`
+	ProfileIDs []string          `json:"profile_ids"`
+	Terminal   string            `json:"terminal,omitempty"` // Overrides the profile and global terminal for this directory
+	Env        map[string]string `json:"env,omitempty"`      // Merged over each profile's Env
+	Activate   string            `json:"activate,omitempty"` // How the directory's own environment is loaded, one of Activations
+	Wrapper    []string          `json:"wrapper,omitempty"`  // Argv prefix for ActivateWrapper, e.g. ["devbox", "run", "--"]
 }
 
+// Environment activations for DirConfig.Activate.
+const (
+	ActivateNone    = ""
+	ActivateDotenv  = "dotenv"  // read .env into the environment, below DirConfig.Env
+	ActivateDirenv  = "direnv"  // run the shell through "direnv exec <dir>"
+	ActivateNix     = "nix"     // run the shell through "nix develop <dir> -c"
+	ActivateWrapper = "wrapper" // run the shell through DirConfig.Wrapper
+)
+
+// Activations lists the activations in the order the TUI cycles through
+// them.
+var Activations = []string{ActivateNone, ActivateDotenv, ActivateDirenv, ActivateNix, ActivateWrapper}
+
 type Config struct {
-	SrcDir      string      `json:"src_dir"`
-	Terminal    string      `json:"terminal"`     // Terminal emulator to use (e.g., "Terminal", "iTerm", "Warp")
-	Profiles    []Profile   `json:"profiles"`
-	Directories []DirConfig `json:"directories"`
+	SrcDir      string           `json:"src_dir"`
+	Terminal    string           `json:"terminal"`            // Terminal emulator to use (e.g., "Terminal", "iTerm", "Warp")
+	Group       bool             `json:"group,omitempty"`     // Open a directory's profiles as tabs of one window where supported
+	Shell       Shell            `json:"shell"`               // Shell profile commands run in
+	Tmux        TmuxConfig       `json:"tmux"`                // Used when Terminal is "tmux"
+	Zellij      ZellijConfig     `json:"zellij"`              // Used when Terminal is "zellij"
+	Terminals   []CustomTerminal `json:"terminals,omitempty"` // User-defined terminals, selectable by name
+	Wrappers    []Wrapper        `json:"wrappers,omitempty"`  // Command prefixes profiles can run through, outermost first
+	Profiles    []Profile        `json:"profiles"`
+	Directories []DirConfig      `json:"directories"`
+}
+
+// Tmux layouts.
+const (
+	TmuxLayoutSession = "session" // one session per directory, one window per profile
+	TmuxLayoutWindow  = "window"  // one window per directory in a shared session, one pane per profile
+)
+
+// TmuxConfig controls the tmux backend.
+type TmuxConfig struct {
+	Layout  string `json:"layout,omitempty"`  // TmuxLayoutSession (default) or TmuxLayoutWindow
+	Session string `json:"session,omitempty"` // Shared session for TmuxLayoutWindow, default "gopener"
+}
+
+// ZellijConfig controls the zellij backend.
+type ZellijConfig struct {
+	Session string `json:"session,omitempty"` // Session to start or add tabs to, default "gopener"
+	Host    string `json:"host,omitempty"`    // Terminal emulator that runs a new session, auto-detected when empty
+}
+
+// CustomTerminal describes a terminal emulator gopener has no built-in
+// support for. Templates are argument lists following Binary; in each
+// argument {dir} is replaced by the directory, {title} by the window title
+// and {cmd} by a command line for sh, as in ["sh", "-c", "{cmd}"], that
+// starts the profile's own shell running the command. An argument that is
+// just {shell} becomes that shell's argv, with its login and interactive
+// options. A template that never mentions {dir} gets a command
+// that changes into the directory first.
+type CustomTerminal struct {
+	Name      string   `json:"name"`                 // Shown in settings and stored in Config.Terminal
+	Binary    string   `json:"binary"`               // Executable to run, e.g. "foot" or "flatpak"
+	Argv      []string `json:"argv"`                 // Opens a profile in a window of its own
+	NewWindow []string `json:"new_window,omitempty"` // Opens the first profile of a group, default Argv
+	Tab       []string `json:"tab,omitempty"`        // Opens a further profile as a tab; enables group mode
+}
+
+// Wrapper is a named command prefix, such as a sandbox or a scheduler
+// setting, that profiles run their shell through. In Argv {dir} is replaced
+// by the directory. A profile using several wrappers nests them in the order
+// of Config.Wrappers, the first outermost.
+type Wrapper struct {
+	Name string   `json:"name"` // Referenced by Profile.Wrappers
+	Argv []string `json:"argv"` // e.g. ["nice", "-n", "10"] or ["firejail", "--whitelist={dir}"]
+}
+
+// FindWrapper returns the wrapper with the given name, or nil.
+func (c *Config) FindWrapper(name string) *Wrapper {
+	for i := range c.Wrappers {
+		if c.Wrappers[i].Name == name {
+			return &c.Wrappers[i]
+		}
+	}
+	return nil
+}
+
+// TerminalFor returns the terminal p opens in for dir: the directory's
+// override, then the profile's, then Config.Terminal. It is "" when a
+// terminal should be detected.
+func (c *Config) TerminalFor(dir DirConfig, p Profile) string {
+	switch {
+	case dir.Terminal != "":
+		return dir.Terminal
+	case p.Terminal != "":
+		return p.Terminal
+	}
+	return c.Terminal
+}
+
+// TerminalOverrides lists the values offered for a profile or directory
+// terminal override: "" to inherit, then TerminalChoices.
+func (c *Config) TerminalOverrides() []string {
+	return append([]string{""}, c.TerminalChoices()...)
+}
+
+// FindTerminal returns the custom terminal with the given name, or nil.
+func (c *Config) FindTerminal(name string) *CustomTerminal {
+	for i := range c.Terminals {
+		if c.Terminals[i].Name == name {
+			return &c.Terminals[i]
+		}
+	}
+	return nil
+}
+
+// TerminalChoices lists the terminals that can be selected: the installed
+// ones from AvailableTerminals followed by the custom ones, with custom
+// definitions taking the place of a built-in terminal of the same name.
+func (c *Config) TerminalChoices() []string {
+	var choices []string
+	for _, t := range AvailableTerminals() {
+		if c.FindTerminal(t) == nil {
+			choices = append(choices, t)
+		}
+	}
+	for _, t := range c.Terminals {
+		choices = append(choices, t.Name)
+	}
+	return choices
 }
 
 func configPath() (string, error) {
@@ -38,6 +225,20 @@ func configPath() (string, error) {
 	return filepath.Join(base, "gopener", "config.json"), nil
 }
 
+// StateDir is where gopener keeps state and logs: $XDG_STATE_HOME/gopener,
+// defaulting to ~/.local/state/gopener.
+func StateDir() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "gopener"), nil
+}
+
 func Load() (*Config, error) {
 	path, err := configPath()
 	if err != nil {
@@ -76,6 +277,20 @@ func (c *Config) Save() error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// Clone returns a deep copy of c's saved settings, for work that runs
+// while c is being edited.
+func (c *Config) Clone() *Config {
+	data, err := json.Marshal(c)
+	if err != nil {
+		panic(err) // a Config always marshals
+	}
+	var out Config
+	if err := json.Unmarshal(data, &out); err != nil {
+		panic(err)
+	}
+	return &out
+}
+
 // FindDir returns the DirConfig for the given path, or nil.
 func (c *Config) FindDir(path string) *DirConfig {
 	for i := range c.Directories {
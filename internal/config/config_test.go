@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -21,6 +23,9 @@ func TestDefault(t *testing.T) {
 		if p.Cmd == "" {
 			t.Errorf("profile %q has empty command", p.Label)
 		}
+		if gui := strings.HasSuffix(p.Cmd, " ."); p.IsGUI() != gui {
+			t.Errorf("profile %q: GUI %v, want %v", p.Label, p.IsGUI(), gui)
+		}
 	}
 }
 
@@ -116,3 +121,247 @@ func TestFindDir(t *testing.T) {
 		t.Errorf("FindDir missing: expected nil, got %+v", got)
 	}
 }
+
+func TestTerminalChoicesIncludesCustom(t *testing.T) {
+	builtin := AvailableTerminals()[0]
+	cfg := &Config{Terminals: []CustomTerminal{
+		{Name: "foot", Binary: "foot"},
+		{Name: builtin, Binary: "my-wrapper"},
+	}}
+
+	choices := cfg.TerminalChoices()
+	count := 0
+	for _, c := range choices {
+		if c == builtin {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("%s should be listed once, got %q", builtin, choices)
+	}
+	if choices[len(choices)-2] != "foot" || choices[len(choices)-1] != builtin {
+		t.Errorf("custom terminals should come last, got %q", choices)
+	}
+	if cfg.FindTerminal("foot") == nil || cfg.FindTerminal("nope") != nil {
+		t.Error("FindTerminal mismatch")
+	}
+}
+
+func TestParseShell(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Shell
+		str     string // canonical String() form
+		wantErr bool
+	}{
+		{"", Shell{}, "", false},
+		{"zsh", Shell{Path: "zsh"}, "zsh", false},
+		{"/usr/bin/fish -l -i", Shell{Path: "/usr/bin/fish", Login: true, Interactive: true}, "/usr/bin/fish -l -i", false},
+		{"bash -li", Shell{Path: "bash", Login: true, Interactive: true}, "bash -l -i", false},
+		{"bash --login", Shell{Path: "bash", Login: true}, "bash -l", false},
+		{"bash -x", Shell{}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseShell(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseShell(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("String() = %q, want %q", got.String(), tt.str)
+		}
+	}
+}
+
+func TestParseArgv(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"  devbox run --  ", []string{"devbox", "run", "--"}, false},
+		{"nix develop --command sh -c 'a b'", []string{"nix", "develop", "--command", "sh", "-c", "a b"}, false},
+		{`sh -c 'echo "$HOME"; id'`, []string{"sh", "-c", `echo "$HOME"; id`}, false},
+		{`a" b"c "x\"y" "\$z"`, []string{"a bc", `x"y`, "$z"}, false},
+		{`with\ space {dir}/x`, []string{"with space", "{dir}/x"}, false},
+		{"''", []string{""}, false},
+		{"sh -c 'a b", nil, true},
+		{`"a b`, nil, true},
+		{`a\`, nil, true},
+		{"a; b", nil, true},
+		{"$HOME/bin", nil, true},
+		{`"$HOME"`, nil, true},
+		{"a | b", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseArgv(tt.in)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseArgv(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestShellFor(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	cfg := &Config{}
+	if got := cfg.ShellFor(Profile{}); got != (Shell{Path: "/bin/zsh"}) {
+		t.Errorf("default: got %+v", got)
+	}
+	cfg.Shell = Shell{Path: "bash", Login: true}
+	if got := cfg.ShellFor(Profile{}); got != cfg.Shell {
+		t.Errorf("global: got %+v", got)
+	}
+	p := Profile{Shell: &Shell{Interactive: true}}
+	if got := cfg.ShellFor(p); got != (Shell{Path: "/bin/zsh", Interactive: true}) {
+		t.Errorf("profile override: got %+v", got)
+	}
+}
+
+func TestTerminalFor(t *testing.T) {
+	cfg := &Config{Terminal: "ghostty"}
+	tests := []struct {
+		dir, profile, want string
+	}{
+		{"", "", "ghostty"},
+		{"", "kitty", "kitty"},
+		{"xterm", "kitty", "xterm"},
+		{"xterm", "", "xterm"},
+	}
+	for _, tt := range tests {
+		got := cfg.TerminalFor(DirConfig{Terminal: tt.dir}, Profile{Terminal: tt.profile})
+		if got != tt.want {
+			t.Errorf("dir %q, profile %q: got %q, want %q", tt.dir, tt.profile, got, tt.want)
+		}
+	}
+	if overrides := cfg.TerminalOverrides(); len(overrides) == 0 || overrides[0] != "" {
+		t.Errorf("overrides should start with inheriting, got %q", overrides)
+	}
+}
+
+func TestEnvFor(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("GOPENER_TEST_UNSET", "")
+	c := &Config{}
+	p := Profile{Env: map[string]string{
+		"CLAUDE_CONFIG_DIR": "${HOME}/.claude-work",
+		"NODE_ENV":          "development",
+		"PRICE":             "$5 or $HOME",
+		"TOKEN":             "cmd:pass show ${HOME}/key",
+	}}
+	dir := DirConfig{Env: map[string]string{
+		"NODE_ENV": "production",
+		"MIRROR":   "${NODE_ENV}-${CLAUDE_CONFIG_DIR}${GOPENER_TEST_UNSET}",
+	}}
+
+	got, err := c.EnvFor(dir, p)
+	if err != nil {
+		t.Fatalf("EnvFor: %v", err)
+	}
+	want := map[string]string{
+		"CLAUDE_CONFIG_DIR": "/home/me/.claude-work",
+		"NODE_ENV":          "production",
+		"PRICE":             "$5 or $HOME",               // only ${VAR} expands
+		"TOKEN":             "cmd:pass show ${HOME}/key", // left for the launcher
+		// Directory values see the profile's, not each other's.
+		"MIRROR": "development-/home/me/.claude-work",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvFor:\n got %v\nwant %v", got, want)
+	}
+
+	if got, err := c.EnvFor(DirConfig{}, Profile{}); got != nil || err != nil {
+		t.Errorf("no env: got %v, %v", got, err)
+	}
+	if _, err := c.EnvFor(DirConfig{Env: map[string]string{"BAD-NAME": "x"}}, p); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+	if _, err := c.EnvFor(DirConfig{Env: map[string]string{"KEY": "file: "}}, p); err == nil {
+		t.Error("expected an error for an empty secret reference")
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"  ", nil, false},
+		{"A=1", map[string]string{"A": "1"}, false},
+		{"A=1  B= C=x=y", map[string]string{"A": "1", "B": "", "C": "x=y"}, false},
+		{`GREETING="hi \"there\"" PATH=${PATH}:/opt/bin`, map[string]string{"GREETING": `hi "there"`, "PATH": "${PATH}:/opt/bin"}, false},
+		{"A", nil, true},
+		{"1A=x", nil, true},
+		{`A="open`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEnv(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseEnv(%q): err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseEnv(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatEnvRoundTrip(t *testing.T) {
+	env := map[string]string{
+		"B":     "plain",
+		"A":     "with space",
+		"QUOTE": `say "hi"`,
+		"SLASH": `C:\dir`,
+		"EMPTY": "",
+		"TAB":   "a\tb",
+	}
+	s := FormatEnv(env)
+	if !strings.HasPrefix(s, `A="with space" B=plain EMPTY= `) {
+		t.Errorf("FormatEnv: got %q", s)
+	}
+	got, err := ParseEnv(s)
+	if err != nil {
+		t.Fatalf("ParseEnv(%q): %v", s, err)
+	}
+	if !reflect.DeepEqual(got, env) {
+		t.Errorf("round trip:\n got %v\nwant %v", got, env)
+	}
+}
+
+func TestParseDotenv(t *testing.T) {
+	data := `# comment
+NODE_ENV=development
+export AWS_PROFILE = work
+EMPTY=
+TRAILING=value # a comment
+HASH=a#b
+SINGLE='literal \n ${HOME} # kept'
+DOUBLE="line\nbreak \"quoted\" # kept"
+REF=${HOME}/bin
+
+`
+	got, err := ParseDotenv(data)
+	if err != nil {
+		t.Fatalf("ParseDotenv: %v", err)
+	}
+	want := map[string]string{
+		"NODE_ENV":    "development",
+		"AWS_PROFILE": "work",
+		"EMPTY":       "",
+		"TRAILING":    "value",
+		"HASH":        "a#b",
+		"SINGLE":      `literal \n ${HOME} # kept`,
+		"DOUBLE":      "line\nbreak \"quoted\" # kept",
+		"REF":         "${HOME}/bin",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDotenv:\n got %q\nwant %q", got, want)
+	}
+
+	for _, bad := range []string{"NOVALUE", "1X=y", `A="open`, "A='open"} {
+		if _, err := ParseDotenv("OK=1\n" + bad); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("ParseDotenv(%q): got %v, want an error on line 2", bad, err)
+		}
+	}
+}
@@ -15,8 +15,8 @@ func Default() *Config {
 		Profiles: []Profile{
 			{ID: newID(), Label: "Claude", Cmd: "claude --continue"},
 			{ID: newID(), Label: "Claude YOLO", Cmd: "claude --continue --dangerously-skip-permissions"},
-			{ID: newID(), Label: "VS Code", Cmd: "code ."},
-			{ID: newID(), Label: "IntelliJ", Cmd: "idea ."},
+			{ID: newID(), Label: "VS Code", Cmd: "code .", Kind: KindGUI},
+			{ID: newID(), Label: "IntelliJ", Cmd: "idea .", Kind: KindGUI},
 		},
 	}
 }
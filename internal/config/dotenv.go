@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseDotenv parses the contents of a .env file: NAME=value lines,
+// optionally starting with "export", with blank lines and # comments
+// ignored. Values may be double-quoted, with \n, \t, \" and \\ escapes, or
+// single-quoted, taken literally; an unquoted value ends at " #". ${VAR}
+// references are left for Config.EnvFor to expand.
+func ParseDotenv(data string) (map[string]string, error) {
+	env := make(map[string]string)
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envName.MatchString(name) {
+			return nil, fmt.Errorf("line %d: expected NAME=value", i+1)
+		}
+		value, err := dotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", i+1, name, err)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+func dotenvValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, "'"):
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quote")
+		}
+		return s[1 : end+1], nil
+	case strings.HasPrefix(s, `"`):
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '"':
+				return sb.String(), nil
+			case c == '\\' && i+1 < len(s):
+				i++
+				switch s[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				case '"', '\\':
+					sb.WriteByte(s[i])
+				default:
+					sb.WriteByte('\\')
+					sb.WriteByte(s[i])
+				}
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated quote")
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	envRef  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// Prefixes of env values that refer to a secret instead of holding it. The
+// launcher resolves them only when it launches, so the config and
+// everything shown in the TUI keep the reference. Only values from the
+// config are references; those read from a directory's .env file are
+// always literal.
+const (
+	SecretCmd  = "cmd:"  // the output of a command run by sh, e.g. "cmd:pass show anthropic/key"
+	SecretFile = "file:" // the contents of a file, e.g. "file:~/.secrets/key"
+)
+
+// DotenvSecret stands in Profile.Secrets for a value read from the
+// directory's .env file, which is never shown.
+const DotenvSecret = "<.env>"
+
+// IsSecret reports whether an env value is a secret reference.
+func IsSecret(v string) bool {
+	return strings.HasPrefix(v, SecretCmd) || strings.HasPrefix(v, SecretFile)
+}
+
+// EnvFor returns the environment p is launched with in dir: p.Env merged
+// with dir.Env, the directory's values taking precedence. ${VAR} in a value
+// is replaced by the variable from gopener's own environment, which for
+// directory values includes p's. Any other $ is left as it is, and secret
+// references are not expanded.
+func (c *Config) EnvFor(dir DirConfig, p Profile) (map[string]string, error) {
+	if len(p.Env) == 0 && len(dir.Env) == 0 {
+		return nil, nil
+	}
+	if err := CheckEnv(p.Env); err != nil {
+		return nil, err
+	}
+	if err := CheckEnv(dir.Env); err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(p.Env)+len(dir.Env))
+	for k, v := range p.Env {
+		env[k] = expandEnv(v, nil)
+	}
+	profile := make(map[string]string, len(env))
+	for k, v := range env {
+		profile[k] = v
+	}
+	for k, v := range dir.Env {
+		env[k] = expandEnv(v, profile)
+	}
+	return env, nil
+}
+
+// expandEnv replaces ${VAR} in s, looking VAR up in vars and then in the
+// process environment.
+func expandEnv(s string, vars map[string]string) string {
+	if IsSecret(s) {
+		return s
+	}
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	})
+}
+
+// CheckEnv reports a variable name a shell could not set, or a secret
+// reference that refers to nothing.
+func CheckEnv(env map[string]string) error {
+	for _, k := range EnvNames(env) {
+		if !envName.MatchString(k) {
+			return fmt.Errorf("invalid variable name %q", k)
+		}
+		v := env[k]
+		if IsSecret(v) && strings.TrimSpace(v[strings.Index(v, ":")+1:]) == "" {
+			return fmt.Errorf("%s: empty secret reference", k)
+		}
+	}
+	return nil
+}
+
+// EnvNames returns the names set in env, sorted.
+func EnvNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for k := range env {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatEnv formats env for display and editing as space-separated
+// NAME=value pairs in name order, e.g. `NODE_ENV=dev GREETING="hi there"`.
+// Values with spaces or quotes are double-quoted.
+func FormatEnv(env map[string]string) string {
+	pairs := make([]string, 0, len(env))
+	for _, k := range EnvNames(env) {
+		v := env[k]
+		if strings.ContainsFunc(v, func(r rune) bool { return unicode.IsSpace(r) || r == '"' || r == '\\' }) {
+			v = strconv.Quote(v)
+		}
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// ParseEnv parses the form written by FormatEnv. An empty string is an
+// empty environment.
+func ParseEnv(s string) (map[string]string, error) {
+	var env map[string]string
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return env, nil
+		}
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok || strings.ContainsFunc(name, unicode.IsSpace) {
+			field, _, _ := strings.Cut(s, " ")
+			return nil, fmt.Errorf("%s: expected NAME=value", field)
+		}
+		if !envName.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable name %q", name)
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%s: unterminated quote", name)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env[name] = value
+		s = rest
+	}
+}
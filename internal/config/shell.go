@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Shell selects the shell profile commands run in.
+type Shell struct {
+	Path        string `json:"path,omitempty"`        // e.g. "zsh" or "/usr/bin/fish"; default $SHELL
+	Login       bool   `json:"login,omitempty"`       // start as a login shell (-l), reading .profile/.zprofile
+	Interactive bool   `json:"interactive,omitempty"` // start interactively (-i), reading .bashrc/.zshrc and aliases
+}
+
+// DefaultShell is $SHELL, or /bin/sh when it is unset.
+func DefaultShell() string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	return "/bin/sh"
+}
+
+// ShellFor returns the shell p runs in: its own setting when it has one,
+// otherwise the global one, with an empty path meaning DefaultShell.
+func (c *Config) ShellFor(p Profile) Shell {
+	sh := c.Shell
+	if p.Shell != nil {
+		sh = *p.Shell
+	}
+	if sh.Path == "" {
+		sh.Path = DefaultShell()
+	}
+	return sh
+}
+
+// ParseShell parses the form shown by Shell.String, e.g. "zsh -l -i". The
+// options may be combined ("-li") or spelled --login and --interactive.
+func ParseShell(s string) (Shell, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return Shell{}, nil
+	}
+	sh := Shell{Path: fields[0]}
+	for _, f := range fields[1:] {
+		switch f {
+		case "-l", "--login":
+			sh.Login = true
+		case "-i", "--interactive":
+			sh.Interactive = true
+		case "-li", "-il":
+			sh.Login, sh.Interactive = true, true
+		default:
+			return Shell{}, fmt.Errorf("unsupported shell option %s (use -l and -i)", f)
+		}
+	}
+	return sh, nil
+}
+
+// String formats sh for display and editing, e.g. "zsh -l -i". An empty
+// path is shown as "".
+func (sh Shell) String() string {
+	if sh.Path == "" {
+		return ""
+	}
+	s := sh.Path
+	if sh.Login {
+		s += " -l"
+	}
+	if sh.Interactive {
+		s += " -i"
+	}
+	return s
+}
+
+// ParseArgv splits s into words the way a POSIX shell would, without
+// expanding anything: single quotes keep their contents as they are,
+// double quotes too except for \", \\, \$ and \`, and a backslash outside
+// quotes keeps the next character. Unclosed quotes, and operators or
+// expansions outside single quotes, are errors rather than passed on
+// literally.
+func ParseArgv(s string) ([]string, error) {
+	var argv []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				argv = append(argv, word.String())
+				word.Reset()
+				inWord = false
+			}
+			continue
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed ' in %s", s[i:])
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				switch s[j] {
+				case '\\':
+					if j+1 < len(s) && strings.IndexByte("\"\\$`", s[j+1]) >= 0 {
+						j++
+					}
+				case '$', '`':
+					return nil, fmt.Errorf("unsupported %c in %s (use single quotes)", s[j], s[i:])
+				}
+				word.WriteByte(s[j])
+			}
+			if j == len(s) {
+				return nil, fmt.Errorf(`unclosed " in %s`, s[i:])
+			}
+			i = j
+		case c == '\\':
+			if i+1 == len(s) {
+				return nil, fmt.Errorf("trailing \\")
+			}
+			i++
+			word.WriteByte(s[i])
+		case strings.IndexByte("$`;&|<>()", c) >= 0:
+			return nil, fmt.Errorf("unsupported %c (quote it to pass it on)", c)
+		default:
+			word.WriteByte(c)
+		}
+		inWord = true
+	}
+	if inWord {
+		argv = append(argv, word.String())
+	}
+	return argv, nil
+}
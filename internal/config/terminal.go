@@ -0,0 +1,33 @@
+package config
+
+import "os/exec"
+
+// Multiplexers are terminal backends that run inside an existing terminal
+// instead of opening windows of their own. They are offered alongside the
+// emulators when installed.
+var Multiplexers = []string{"tmux", "zellij"}
+
+// IsMultiplexer reports whether name is one of Multiplexers.
+func IsMultiplexer(name string) bool {
+	return contains(Multiplexers, name)
+}
+
+// availableMultiplexers returns the installed multiplexers.
+func availableMultiplexers() []string {
+	var available []string
+	for _, m := range Multiplexers {
+		if _, err := exec.LookPath(m); err == nil {
+			available = append(available, m)
+		}
+	}
+	return available
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
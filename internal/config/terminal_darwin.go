@@ -7,6 +7,11 @@ import (
 	"path/filepath"
 )
 
+// DefaultExitPolicy is the exit policy of profiles that set none. On macOS
+// the scripted terminals have always left their login shell open once the
+// command exits.
+const DefaultExitPolicy = ExitShell
+
 // DetectTerminal attempts to auto-detect the current terminal emulator on macOS.
 // It first checks what terminal is currently running gopener, then falls back to
 // checking for installed terminals in order of preference.
@@ -107,14 +112,5 @@ func AvailableTerminals() []string {
 		available = append(available, "Terminal")
 	}
 
-	return available
-}
-
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
+	return append(available, availableMultiplexers()...)
 }
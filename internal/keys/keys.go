@@ -23,6 +23,12 @@ type MainKeys struct {
 	Profiles  key.Binding
 	Settings  key.Binding
 	Start     key.Binding
+	Open      key.Binding
+	OpenOne   key.Binding
+	Stop      key.Binding
+	StopAll   key.Binding
+	DryRun    key.Binding
+	Cancel    key.Binding
 	Rescan    key.Binding
 	ChangeSrc key.Binding
 	Quit      key.Binding
@@ -38,6 +44,12 @@ var Main = MainKeys{
 	Profiles:  key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "profiles")),
 	Settings:  key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "settings")),
 	Start:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start")),
+	Open:      key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open this dir")),
+	OpenOne:   key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "open one profile")),
+	Stop:      key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "stop a session")),
+	StopAll:   key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "stop all sessions")),
+	DryRun:    key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "dry run")),
+	Cancel:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel launch")),
 	Rescan:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rescan")),
 	ChangeSrc: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "change src dir")),
 	Quit:      key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
@@ -62,31 +74,73 @@ var Profile = ProfileKeys{
 }
 
 type AssignKeys struct {
+	Up       key.Binding
+	Down     key.Binding
+	Toggle   key.Binding
+	Terminal key.Binding
+	Env      key.Binding
+	Activate key.Binding
+	Confirm  key.Binding
+	Back     key.Binding
+}
+
+var Assign = AssignKeys{
+	Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Toggle:   key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle")),
+	Terminal: key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "terminal")),
+	Env:      key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "environment")),
+	Activate: key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "activation")),
+	Confirm:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
+	Back:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+}
+
+type PickKeys struct {
 	Up      key.Binding
 	Down    key.Binding
-	Toggle  key.Binding
 	Confirm key.Binding
 	Back    key.Binding
 }
 
-var Assign = AssignKeys{
+var Pick = PickKeys{
 	Up:      key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
 	Down:    key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
-	Toggle:  key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle")),
-	Confirm: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
-	Back:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+	Confirm: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open")),
+	Back:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
 }
 
 type SettingsKeys struct {
-	Up     key.Binding
-	Down   key.Binding
-	Select key.Binding
-	Back   key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	Select     key.Binding
+	TmuxLayout key.Binding
+	Group      key.Binding
+	Shell      key.Binding
+	Back       key.Binding
 }
 
 var Settings = SettingsKeys{
-	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
-	Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
-	Select: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
-	Back:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+	Up:         key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:       key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Select:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+	TmuxLayout: key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "tmux layout")),
+	Group:      key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "group as tabs")),
+	Shell:      key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "shell")),
+	Back:       key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+}
+
+type PlanKeys struct {
+	Up      key.Binding
+	Down    key.Binding
+	Toggle  key.Binding
+	Confirm key.Binding
+	Back    key.Binding
+}
+
+var Plan = PlanKeys{
+	Up:      key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:    key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Toggle:  key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle")),
+	Confirm: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "launch")),
+	Back:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
 }
@@ -0,0 +1,64 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// activation resolves dir's environment activation into the variables it
+// reads from the directory and the argv its profiles' shells are started
+// through. A directory in dotenv mode without a .env file has no
+// variables.
+func activation(dir config.DirConfig) (env map[string]string, wrap []string, err error) {
+	switch dir.Activate {
+	case config.ActivateNone:
+		return nil, nil, nil
+	case config.ActivateDotenv:
+		data, err := os.ReadFile(filepath.Join(dir.Path, ".env"))
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		env, err := config.ParseDotenv(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf(".env: %v", err)
+		}
+		return env, nil, nil
+	case config.ActivateDirenv:
+		return nil, []string{"direnv", "exec", dir.Path}, nil
+	case config.ActivateNix:
+		return nil, []string{"nix", "develop", dir.Path, "-c"}, nil
+	case config.ActivateWrapper:
+		if len(dir.Wrapper) == 0 {
+			return nil, nil, fmt.Errorf("activation wrapper has no command")
+		}
+		wrap := make([]string, len(dir.Wrapper))
+		for i, arg := range dir.Wrapper {
+			wrap[i] = strings.ReplaceAll(arg, "{dir}", dir.Path)
+		}
+		return nil, wrap, nil
+	}
+	return nil, nil, fmt.Errorf("unknown activation %q", dir.Activate)
+}
+
+// withDotenv returns dir with env merged under its own Env, which wins.
+func withDotenv(dir config.DirConfig, env map[string]string) config.DirConfig {
+	if len(env) == 0 {
+		return dir
+	}
+	merged := make(map[string]string, len(env)+len(dir.Env))
+	for k, v := range env {
+		merged[k] = v
+	}
+	for k, v := range dir.Env {
+		merged[k] = v
+	}
+	dir.Env = merged
+	return dir
+}
@@ -0,0 +1,132 @@
+package launcher
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestActivation(t *testing.T) {
+	tests := []struct {
+		dir     config.DirConfig
+		wrap    []string
+		wantErr bool
+	}{
+		{config.DirConfig{Path: "/src/web"}, nil, false},
+		{config.DirConfig{Path: "/src/web", Activate: config.ActivateDirenv}, []string{"direnv", "exec", "/src/web"}, false},
+		{config.DirConfig{Path: "/src/web", Activate: config.ActivateNix}, []string{"nix", "develop", "/src/web", "-c"}, false},
+		{config.DirConfig{Path: "/src/web", Activate: config.ActivateWrapper, Wrapper: []string{"devbox", "run", "-c", "{dir}/devbox.json", "--"}},
+			[]string{"devbox", "run", "-c", "/src/web/devbox.json", "--"}, false},
+		{config.DirConfig{Path: "/src/web", Activate: config.ActivateWrapper}, nil, true},
+		{config.DirConfig{Path: "/src/web", Activate: "conda"}, nil, true},
+		// No .env file is not an error.
+		{config.DirConfig{Path: t.TempDir(), Activate: config.ActivateDotenv}, nil, false},
+	}
+	for _, tt := range tests {
+		env, wrap, err := activation(tt.dir)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.dir.Activate, err, tt.wantErr)
+		}
+		if env != nil || !reflect.DeepEqual(wrap, tt.wrap) {
+			t.Errorf("%q: got env %v, wrap %q, want wrap %q", tt.dir.Activate, env, wrap, tt.wrap)
+		}
+	}
+}
+
+func TestNewPlanDotenv(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	dir := t.TempDir()
+	dotenv := "NODE_ENV=development\nAPI_URL=http://localhost\nBIN=${HOME}/bin\n"
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(dotenv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", "/home/me")
+	cfg := &config.Config{
+		Terminal: "xterm",
+		Profiles: []config.Profile{{ID: "p1", Label: "Claude", Cmd: "claude",
+			Env: map[string]string{"NODE_ENV": "test", "TOOL": "claude"}}},
+		Directories: []config.DirConfig{{Path: dir, Name: "web", Enabled: true, ProfileIDs: []string{"p1"},
+			Activate: config.ActivateDotenv, Env: map[string]string{"API_URL": "https://staging"}}},
+	}
+
+	plan := NewPlan(cfg, cfg.Directories)
+	// The .env values are hidden from the plan.
+	p := plan.Commands[0].Profile
+	want := map[string]string{"API_URL": "https://staging", "TOOL": "claude"}
+	if !reflect.DeepEqual(p.Env, want) {
+		t.Errorf("env:\n got %v\nwant %v", p.Env, want)
+	}
+	hidden := map[string]string{"NODE_ENV": config.DotenvSecret, "BIN": config.DotenvSecret}
+	if !reflect.DeepEqual(p.Secrets, hidden) || p.EnvFile != envFilePlaceholder {
+		t.Errorf("secrets %v, file %q", p.Secrets, p.EnvFile)
+	}
+
+	// The .env file wins over the profile, the directory's own Env over
+	// the .env file.
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	plan = newPlan(cfg, cfg.Directories, newSecrets(context.Background()))
+	data, err := os.ReadFile(plan.Commands[0].Profile.EnvFile)
+	if err != nil || string(data) != "export BIN='/home/me/bin'\nexport NODE_ENV='development'\n" {
+		t.Errorf("env file holds %q, %v", data, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("not a pair\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plan = NewPlan(cfg, cfg.Directories)
+	if len(plan.Commands) != 0 || len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0].Message, ".env: line 1") {
+		t.Errorf("a broken .env should warn about the directory, got %+v, %+v", plan.Commands, plan.Warnings)
+	}
+}
+
+func TestNewPlanWrapsShell(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	cfg := planFixture()
+	cfg.Terminal = TerminalTmux
+	cfg.Directories[1].Activate = config.ActivateDirenv
+
+	plan := NewPlan(cfg, cfg.Directories)
+	for _, c := range plan.Commands {
+		if cmd := c.Argv[len(c.Argv)-1]; !strings.HasPrefix(cmd, "direnv exec /src/web /bin/sh -c ") {
+			t.Errorf("%s: tmux command %q should run the shell through direnv", c.Profile.Label, cmd)
+		}
+	}
+}
+
+// TestWrapperRuns checks that the command runs inside the wrapper, with
+// the profile's own variables winning over the wrapper's.
+func TestWrapperRuns(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	p := config.Profile{
+		Shell: &config.Shell{Path: sh},
+		Env:   map[string]string{"GOPENER_B": "profile"},
+		Wrap:  []string{"env", "GOPENER_A=wrapper", "GOPENER_B=wrapper"},
+	}
+	argv := shellArgv(p, `echo "$GOPENER_A $GOPENER_B"`)
+	out, err := exec.Command(argv[0], argv[1:]...).Output()
+	if err != nil {
+		t.Fatalf("%q: %v", argv, err)
+	}
+	if got := string(out); got != "wrapper profile\n" {
+		t.Errorf("got %q", got)
+	}
+
+	// Typed and templated commands are wrapped as a command line.
+	line := wrapCommand(p, sh, `echo "$GOPENER_A"`)
+	out, err = exec.Command(sh, "-c", line).Output()
+	if err != nil {
+		t.Fatalf("%q: %v", line, err)
+	}
+	if got := string(out); got != "wrapper\n" {
+		t.Errorf("wrapCommand: got %q", got)
+	}
+}
@@ -0,0 +1,105 @@
+package launcher
+
+import (
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// windowArgv is the argv opening p in dir in a window of its own, using the
+// custom definition of term when cfg has one.
+func windowArgv(cfg *config.Config, term string, dir config.DirConfig, p config.Profile) []string {
+	if t := cfg.FindTerminal(term); t != nil {
+		return customArgv(t, t.Argv, dir, p)
+	}
+	return buildArgv(term, dir, p)
+}
+
+// customArgv expands one of t's templates for p in dir, or returns nil when
+// t has no binary or the template is empty. An argument that is just
+// {shell} becomes the argv of p's shell running the command, as for a
+// built-in terminal. {cmd} is an sh command line starting that same shell,
+// since the template names the shell that runs it.
+func customArgv(t *config.CustomTerminal, tmpl []string, dir config.DirConfig, p config.Profile) []string {
+	if t.Binary == "" || len(tmpl) == 0 {
+		return nil
+	}
+	cd := !mentions(tmpl, "{dir}")
+	r := strings.NewReplacer("{dir}", dir.Path, "{title}", windowTitle(dir, p), "{cmd}", posixCmd(p, dir, cd))
+	argv := []string{t.Binary}
+	for _, arg := range tmpl {
+		if arg == "{shell}" {
+			cmd := p.Cmd
+			if cd {
+				cmd = cdCommand(profileShell(p).Path, dir.Path, cmd)
+			}
+			argv = append(argv, shellArgv(p, cmd)...)
+			continue
+		}
+		argv = append(argv, r.Replace(arg))
+	}
+	return argv
+}
+
+// posixCmd is the command line {cmd} stands for: p's shell with its
+// options running p's command, as for {shell}, quoted for sh and changing
+// into dir first when cd is set.
+func posixCmd(p config.Profile, dir config.DirConfig, cd bool) string {
+	cmd := joinFor("sh", shellArgv(p, p.Cmd))
+	if cd {
+		cmd = cdCommand("sh", dir.Path, cmd)
+	}
+	return cmd
+}
+
+func mentions(tmpl []string, placeholder string) bool {
+	for _, arg := range tmpl {
+		if strings.Contains(arg, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// customGroupArgv is the argv for the i-th profile of a directory in group
+// mode, or nil when t defines no tab template.
+func customGroupArgv(t *config.CustomTerminal, dir config.DirConfig, p config.Profile, i int) []string {
+	if len(t.Tab) == 0 {
+		return nil
+	}
+	if i > 0 {
+		return customArgv(t, t.Tab, dir, p)
+	}
+	if len(t.NewWindow) > 0 {
+		return customArgv(t, t.NewWindow, dir, p)
+	}
+	return customArgv(t, t.Argv, dir, p)
+}
+
+// customGrouper runs a custom terminal's new-window and tab templates. The
+// templates address "the current window" themselves, so there is no handle
+// beyond the terminal's name.
+type customGrouper struct {
+	term  *config.CustomTerminal
+	spawn func(c Command, argv []string) error // opens a window
+	start func(argv []string) error            // opens a tab
+}
+
+func newCustomGrouper(t *config.CustomTerminal) *customGrouper {
+	return &customGrouper{
+		term:  t,
+		spawn: startArgv,
+		start: startReaped,
+	}
+}
+
+func (g *customGrouper) window(c Command) (string, error) {
+	if err := g.spawn(c, customGroupArgv(g.term, c.Dir, c.Profile, 0)); err != nil {
+		return "", err
+	}
+	return g.term.Name, nil
+}
+
+func (g *customGrouper) tab(_ string, c Command) error {
+	return g.start(customGroupArgv(g.term, c.Dir, c.Profile, 1))
+}
@@ -0,0 +1,121 @@
+package launcher
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestCustomArgv(t *testing.T) {
+	dir := config.DirConfig{Path: "/src/my app", Name: "my app"}
+	p := config.Profile{ID: "p1", Label: "Claude", Cmd: "claude --continue", Shell: &config.Shell{Path: "sh"}}
+	bash := p
+	bash.Shell = &config.Shell{Path: "bash", Login: true}
+	bash.Env = map[string]string{"A": "it's"}
+	fish := p
+	fish.Shell = &config.Shell{Path: "fish", Login: true}
+	fish.Env = map[string]string{"A": "it's"}
+	fishCmd := fish
+	fishCmd.Cmd = `claude 'it\'s'` // rendered for fish
+
+	tests := []struct {
+		name string
+		term config.CustomTerminal
+		p    config.Profile
+		want []string
+	}{
+		{
+			"dir flag",
+			config.CustomTerminal{Binary: "foot", Argv: []string{"--working-directory={dir}", "--title", "{title}", "sh", "-c", "{cmd}"}},
+			p,
+			[]string{"foot", "--working-directory=/src/my app", "--title", "my app · Claude", "sh", "-c", "sh -c 'claude --continue'"},
+		},
+		{
+			"no dir placeholder changes directory in cmd",
+			config.CustomTerminal{Binary: "flatpak", Argv: []string{"run", "com.example.Term", "-e", "sh", "-c", "{cmd}"}},
+			p,
+			[]string{"flatpak", "run", "com.example.Term", "-e", "sh", "-c", `cd '/src/my app' && sh -c 'claude --continue'`},
+		},
+		{
+			"cmd runs a bash profile's command in bash with its options",
+			config.CustomTerminal{Binary: "foot", Argv: []string{"sh", "-c", "{cmd}"}},
+			bash,
+			[]string{"foot", "sh", "-c", "cd '/src/my app' && bash -l -c " + quotePOSIX("export A='it'\\''s'\nclaude --continue")},
+		},
+		{
+			"cmd runs a fish profile's command in fish",
+			config.CustomTerminal{Binary: "foot", Argv: []string{"sh", "-c", "{cmd}"}},
+			fishCmd,
+			[]string{"foot", "sh", "-c", "cd '/src/my app' && fish -l -c " + quotePOSIX("set -gx A 'it\\'s'\nclaude 'it\\'s'")},
+		},
+		{
+			"shell is the profile's shell with its options",
+			config.CustomTerminal{Binary: "foot", Argv: []string{"--working-directory={dir}", "{shell}"}},
+			fish,
+			[]string{"foot", "--working-directory=/src/my app", "fish", "-l", "-c", "set -gx A 'it\\'s'\nclaude --continue"},
+		},
+		{
+			"no binary",
+			config.CustomTerminal{Argv: []string{"{cmd}"}},
+			p,
+			nil,
+		},
+		{
+			"no template",
+			config.CustomTerminal{Binary: "foot"},
+			p,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := customArgv(&tt.term, tt.term.Argv, dir, tt.p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// tilix is a custom terminal with every template.
+var tilix = config.CustomTerminal{
+	Name:      "tilix",
+	Binary:    "tilix",
+	Argv:      []string{"--working-directory", "{dir}", "-e", "{shell}"},
+	NewWindow: []string{"--new-window", "--working-directory", "{dir}", "-e", "{cmd}"},
+	Tab:       []string{"--action", "app-new-session", "--working-directory", "{dir}", "-e", "{cmd}"},
+}
+
+func TestNewPlanUsesCustomTerminal(t *testing.T) {
+	cfg := testConfig("tilix", testDir("web", "p1", "p2"))
+	cfg.Terminals = []config.CustomTerminal{tilix}
+	cfg.Shell = config.Shell{Path: "fish", Login: true}
+	plan := NewPlan(cfg, cfg.Directories)
+	// {shell} keeps the profile's shell and its options.
+	want := []string{"tilix", "--working-directory", "/src/web", "-e", "fish", "-l", "-c", "bash"}
+	if got := plan.Commands[1].Argv; !reflect.DeepEqual(got, want) {
+		t.Errorf("\n got %q\nwant %q", got, want)
+	}
+
+	cfg.Group = true
+	plan = NewPlan(cfg, cfg.Directories)
+	if got := plan.Commands[0].Argv; !slices.Contains(got, "--new-window") {
+		t.Errorf("group window: got %q", got)
+	}
+	if got := plan.Commands[1].Argv; !slices.Contains(got, "app-new-session") {
+		t.Errorf("group tab: got %q", got)
+	}
+}
+
+func TestCustomGrouper(t *testing.T) {
+	cfg := testConfig("tilix", testDir("web", "p1", "p2"))
+	cfg.Terminals = []config.CustomTerminal{tilix}
+	if !CanGroup(cfg, "tilix") {
+		t.Fatal("a custom terminal with a tab template should group")
+	}
+	cfg.Terminals[0].Tab = nil
+	if CanGroup(cfg, "tilix") {
+		t.Error("a custom terminal without a tab template should not group")
+	}
+}
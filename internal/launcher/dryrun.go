@@ -0,0 +1,46 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// DryRun is a Launcher that resolves commands exactly like the real launcher
+// but never starts them, nor reads secrets: the env file commands source is
+// a placeholder. Every resolved command is appended to Commands and,
+// when Out is set, printed to it one per line. Results report the commands
+// as StatusPlanned.
+type DryRun struct {
+	Out      io.Writer
+	Commands []Command
+}
+
+// NewDryRun returns a DryRun that prints to w.
+func NewDryRun(w io.Writer) *DryRun {
+	return &DryRun{Out: w}
+}
+
+func (d *DryRun) Launch(ctx context.Context, req Request) []Result {
+	plan := NewPlan(req.Config, req.Dirs)
+
+	results := plan.run(ctx, req.Progress, func(c Command) error {
+		d.Commands = append(d.Commands, c)
+		if d.Out != nil {
+			fmt.Fprintf(d.Out, "# %s → %s\n%s\n", c.Dir.Name, c.Profile.Label, JoinArgv(c.Argv))
+		}
+		return nil
+	})
+	for i := range results {
+		if results[i].Status == StatusLaunched {
+			results[i].Status = StatusPlanned
+		}
+	}
+	return results
+}
+
+// JoinArgv renders argv as a single line that can be pasted into a POSIX
+// shell. Arguments containing anything but safe characters are single-quoted.
+func JoinArgv(argv []string) string {
+	return joinFor("sh", argv)
+}
@@ -0,0 +1,52 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestDryRunRecordsAndPrints(t *testing.T) {
+	var buf bytes.Buffer
+	dr := NewDryRun(&buf)
+	cfg := &config.Config{
+		Terminal: "xterm",
+		Profiles: []config.Profile{{ID: "p1", Label: "Claude", Cmd: "claude"}},
+	}
+	dirs := []config.DirConfig{{Path: "/src/web", Name: "web", Enabled: true, ProfileIDs: []string{"p1"}}}
+
+	results := dr.Launch(context.Background(), Request{Config: cfg, Dirs: dirs})
+	if len(results) != 1 || results[0].Status != StatusPlanned {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if len(dr.Commands) != 1 {
+		t.Fatalf("expected 1 recorded command, got %d", len(dr.Commands))
+	}
+	out := buf.String()
+	if !strings.Contains(out, "# web → Claude") {
+		t.Errorf("missing header in output %q", out)
+	}
+	if !strings.Contains(out, JoinArgv(dr.Commands[0].Argv)) {
+		t.Errorf("missing argv in output %q", out)
+	}
+}
+
+func TestJoinArgv(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want string
+	}{
+		{[]string{"xterm", "-e", "bash"}, "xterm -e bash"},
+		{[]string{"bash", "-c", "cd /x && ls"}, "bash -c 'cd /x && ls'"},
+		{[]string{"echo", "it's"}, `echo 'it'\''s'`},
+		{[]string{"echo", ""}, "echo ''"},
+	}
+	for _, tt := range tests {
+		if got := JoinArgv(tt.argv); got != tt.want {
+			t.Errorf("JoinArgv(%q): got %q, want %q", tt.argv, got, tt.want)
+		}
+	}
+}
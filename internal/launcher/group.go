@@ -0,0 +1,88 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// grouper opens all profiles of a directory in one terminal window: the
+// first profile opens the window and the others become tabs in it.
+type grouper interface {
+	// window opens c in a new window and returns a handle addressing it.
+	// An error means c was not opened. An empty handle with a nil error
+	// means c was opened but the window cannot take tabs, so the rest of
+	// the directory falls back to separate windows.
+	window(c Command) (string, error)
+	// tab opens c as a new tab of the window behind handle.
+	tab(handle string, c Command) error
+}
+
+// CanGroup reports whether term can open a directory's profiles as tabs of
+// one window. Other terminals fall back to a window per profile.
+func CanGroup(cfg *config.Config, term string) bool {
+	return groupFor(cfg, term) != nil
+}
+
+// groupFor returns the grouper for term, preferring a custom definition
+// with a tab template, or nil when term cannot group.
+func groupFor(cfg *config.Config, term string) grouper {
+	if t := cfg.FindTerminal(term); t != nil {
+		if len(t.Tab) == 0 {
+			return nil
+		}
+		return newCustomGrouper(t)
+	}
+	return builtinGrouper(term)
+}
+
+// groupArgv is the argv shown in plans and dry runs for the i-th profile of
+// a directory in group mode, or nil when term cannot group.
+func groupArgv(cfg *config.Config, term string, dir config.DirConfig, p config.Profile, i int) []string {
+	if t := cfg.FindTerminal(term); t != nil {
+		return customGroupArgv(t, dir, p, i)
+	}
+	return builtinGroupArgv(term, dir, p, i)
+}
+
+// launchGrouped runs the plan with one window per directory, falling back
+// to separate windows when the terminal refuses to group.
+func launchGrouped(ctx context.Context, cfg *config.Config, plan Plan, progress func(Progress), g grouper) []Result {
+	handles := make(map[string]string) // directory path → window handle, "" to fall back
+	return plan.run(ctx, progress, withGUI(func(c Command) error {
+		h, ok := handles[c.Dir.Path]
+		switch {
+		case ok && h != "":
+			return g.tab(h, c)
+		case ok:
+			return startWindow(cfg, c)
+		}
+		h, err := g.window(c)
+		handles[c.Dir.Path] = h
+		if err != nil {
+			return startWindow(cfg, c)
+		}
+		return nil
+	}))
+}
+
+// startWindow opens c in a window of its own, as an ungrouped launch would.
+func startWindow(cfg *config.Config, c Command) error {
+	return startArgv(c, windowArgv(cfg, c.Terminal, c.Dir, c.Profile))
+}
+
+// execOutput runs argv and returns its trimmed stdout, folding stderr into
+// the error.
+func execOutput(argv ...string) (string, error) {
+	out, err := exec.Command(argv[0], argv[1:]...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("%s: %s", argv[0], strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
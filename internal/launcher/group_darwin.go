@@ -0,0 +1,78 @@
+//go:build darwin
+
+package launcher
+
+import (
+	"fmt"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// Only iTerm exposes tabs to AppleScript. Ghostty has no API for opening
+// tabs in a running instance, and Terminal.app and Warp can only be driven
+// by keystrokes, so they keep opening a window per profile.
+func builtinGrouper(term string) grouper {
+	if term == "iTerm" {
+		return &itermGrouper{output: execOutput}
+	}
+	return nil
+}
+
+// builtinGroupArgv is the argv shown in plans and dry runs for the i-th
+// profile of a directory in group mode, or nil when term cannot group.
+// Handles that only exist once the window is open are shown as placeholders.
+func builtinGroupArgv(term string, dir config.DirConfig, p config.Profile, i int) []string {
+	if term != "iTerm" {
+		return nil
+	}
+	if i == 0 {
+		return itermWindowArgv(dir, p)
+	}
+	return itermTabArgv("<window>", dir, p)
+}
+
+// itermGrouper opens a window per directory and a tab per further profile,
+// addressing the window by the ID the first script returns.
+type itermGrouper struct {
+	output func(argv ...string) (string, error)
+}
+
+func (g *itermGrouper) window(c Command) (string, error) {
+	return g.output(itermWindowArgv(c.Dir, c.Profile)...)
+}
+
+func (g *itermGrouper) tab(id string, c Command) error {
+	_, err := g.output(itermTabArgv(id, c.Dir, c.Profile)...)
+	return err
+}
+
+func itermWindowArgv(dir config.DirConfig, p config.Profile) []string {
+	script := fmt.Sprintf(
+		`tell application "iTerm"
+			set w to (create window with default profile)
+			tell current session of w
+				set name to "%s"
+				write text "%s"
+			end tell
+			return id of w
+		end tell`,
+		escapeAppleScript(windowTitle(dir, p)), escapeAppleScript(typedCommand(dir, p)),
+	)
+	return []string{"osascript", "-e", script}
+}
+
+func itermTabArgv(id string, dir config.DirConfig, p config.Profile) []string {
+	script := fmt.Sprintf(
+		`tell application "iTerm"
+			tell window id %s
+				set t to (create tab with default profile)
+				tell current session of t
+					set name to "%s"
+					write text "%s"
+				end tell
+			end tell
+		end tell`,
+		id, escapeAppleScript(windowTitle(dir, p)), escapeAppleScript(typedCommand(dir, p)),
+	)
+	return []string{"osascript", "-e", script}
+}
@@ -0,0 +1,157 @@
+//go:build linux
+
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/terminal"
+)
+
+// Ghostty has no external API for opening tabs in a running window, so it
+// is not grouped and keeps opening a window per profile.
+func builtinGrouper(term string) grouper {
+	switch term {
+	case "kitty":
+		return newKittyGrouper()
+	case "wezterm":
+		return &weztermGrouper{output: execOutput}
+	}
+	return nil
+}
+
+// builtinGroupArgv is the argv shown in plans and dry runs for the i-th
+// profile of a directory in group mode, or nil when term cannot group.
+// Handles that only exist once the window is open are shown as placeholders.
+func builtinGroupArgv(term string, dir config.DirConfig, p config.Profile, i int) []string {
+	switch term {
+	case "kitty":
+		if i == 0 {
+			return kittyWindowArgv("<socket>", dir, p)
+		}
+		return kittyTabArgv("<socket>", dir, p)
+	case "wezterm":
+		if i == 0 {
+			return weztermWindowArgv(dir, p)
+		}
+		return weztermTabArgv("<pane>", dir, p)
+	}
+	return nil
+}
+
+// binary is the executable of the registered terminal name, found the way
+// buildArgv finds it.
+func binary(name string) string {
+	t, _ := terminal.Lookup(name)
+	return t.Binary()
+}
+
+// kittyGrouper starts each window listening on its own socket with remote
+// control allowed, then opens tabs with "kitty @ launch" over that socket.
+type kittyGrouper struct {
+	start   func(c Command, argv []string) error
+	run     func(argv ...string) (string, error)
+	socket  func() (string, error) // a path no window has listened on
+	timeout time.Duration          // how long to wait for a new window's socket
+}
+
+func newKittyGrouper() *kittyGrouper {
+	return &kittyGrouper{
+		start:   startArgv,
+		run:     execOutput,
+		socket:  kittySocket,
+		timeout: 5 * time.Second,
+	}
+}
+
+// kittySocket returns a socket path in a new directory, so the socket found
+// there can only be the new window's, never one left by an earlier window.
+// The directories live next to the env files and are pruned like them, as
+// the window keeps listening long after its tabs are open.
+func kittySocket() (string, error) {
+	files, err := envFileDir()
+	if err != nil {
+		return "", err
+	}
+	pruneStale(files, "kitty-*")
+	dir, err := os.MkdirTemp(files, "kitty-*")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sock"), nil
+}
+
+func (k *kittyGrouper) window(c Command) (string, error) {
+	sock, err := k.socket()
+	if err != nil {
+		return "", err
+	}
+	if err := k.start(c, kittyWindowArgv(sock, c.Dir, c.Profile)); err != nil {
+		return "", err
+	}
+	for deadline := time.Now().Add(k.timeout); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if _, err := os.Stat(sock); err == nil {
+			return sock, nil
+		}
+	}
+	return "", nil
+}
+
+func (k *kittyGrouper) tab(sock string, c Command) error {
+	_, err := k.run(kittyTabArgv(sock, c.Dir, c.Profile)...)
+	return err
+}
+
+func kittyWindowArgv(sock string, dir config.DirConfig, p config.Profile) []string {
+	return append([]string{binary("kitty"), "-o", "allow_remote_control=yes", "--listen-on", "unix:" + sock,
+		"--directory", dir.Path, "--title", windowTitle(dir, p)}, shellArgv(p, p.Cmd)...)
+}
+
+func kittyTabArgv(sock string, dir config.DirConfig, p config.Profile) []string {
+	return append([]string{binary("kitty"), "@", "--to", "unix:" + sock, "launch", "--type=tab",
+		"--tab-title", windowTitle(dir, p), "--cwd", dir.Path}, shellArgv(p, p.Cmd)...)
+}
+
+// weztermGrouper spawns windows and tabs through the mux of a running
+// wezterm. Without one, "wezterm cli" fails and the launch falls back.
+type weztermGrouper struct {
+	output func(argv ...string) (string, error)
+}
+
+func (w *weztermGrouper) window(c Command) (string, error) {
+	pane, err := w.output(weztermWindowArgv(c.Dir, c.Profile)...)
+	if err != nil {
+		return "", err
+	}
+	w.setTitle(pane, windowTitle(c.Dir, c.Profile))
+	return pane, nil
+}
+
+func (w *weztermGrouper) tab(pane string, c Command) error {
+	id, err := w.output(weztermTabArgv(pane, c.Dir, c.Profile)...)
+	if err != nil {
+		return err
+	}
+	w.setTitle(id, windowTitle(c.Dir, c.Profile))
+	return nil
+}
+
+// setTitle names the tab holding pane. Failing to is not worth a failed
+// launch: wezterm releases before set-tab-title simply keep the default.
+func (w *weztermGrouper) setTitle(pane, title string) {
+	_, _ = w.output(binary("wezterm"), "cli", "set-tab-title", "--pane-id", pane, title)
+}
+
+func weztermWindowArgv(dir config.DirConfig, p config.Profile) []string {
+	return append([]string{binary("wezterm"), "cli", "--no-auto-start", "spawn", "--new-window",
+		"--cwd", dir.Path, "--"}, shellArgv(p, p.Cmd)...)
+}
+
+// weztermTabArgv spawns a tab in the window that contains pane.
+func weztermTabArgv(pane string, dir config.DirConfig, p config.Profile) []string {
+	return append([]string{binary("wezterm"), "cli", "--no-auto-start", "spawn", "--pane-id", pane,
+		"--cwd", dir.Path, "--"}, shellArgv(p, p.Cmd)...)
+}
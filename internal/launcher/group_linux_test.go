@@ -0,0 +1,216 @@
+//go:build linux
+
+package launcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// fakeGrouper records windows and tabs, refusing windows for directories
+// listed in refuse.
+type fakeGrouper struct {
+	refuse  map[string]error
+	noTabs  bool
+	windows []string
+	tabs    []string
+}
+
+func (f *fakeGrouper) window(c Command) (string, error) {
+	if err := f.refuse[c.Dir.Name]; err != nil {
+		return "", err
+	}
+	f.windows = append(f.windows, c.Dir.Name+" → "+c.Profile.Label)
+	if f.noTabs {
+		return "", nil
+	}
+	return "win-" + c.Dir.Name, nil
+}
+
+func (f *fakeGrouper) tab(h string, c Command) error {
+	f.tabs = append(f.tabs, h+":"+c.Profile.Label)
+	return nil
+}
+
+func TestLaunchGroupedOpensTabs(t *testing.T) {
+	g := &fakeGrouper{}
+	// Fallback windows use a terminal that does not exist, so they fail
+	// visibly instead of opening anything.
+	cfg := testConfig("gopener-no-such-terminal", testDir("web", "p1", "p2"), testDir("api", "p1", "p2"))
+	cfg.Group = true
+	plan := NewPlan(cfg, cfg.Directories)
+	results := launchGrouped(context.Background(), cfg, plan, nil, g)
+	if got := statuses(results); got != "launched,launched,launched,launched" {
+		t.Fatalf("statuses: %s", got)
+	}
+	if want := []string{"web → Claude", "api → Claude"}; !reflect.DeepEqual(g.windows, want) {
+		t.Errorf("windows: got %q, want %q", g.windows, want)
+	}
+	if want := []string{"win-web:Shell", "win-api:Shell"}; !reflect.DeepEqual(g.tabs, want) {
+		t.Errorf("tabs: got %q, want %q", g.tabs, want)
+	}
+}
+
+func TestLaunchGroupedFallsBack(t *testing.T) {
+	g := &fakeGrouper{refuse: map[string]error{"web": errors.New("no remote control")}}
+	cfg := testConfig("gopener-no-such-terminal", testDir("web", "p1", "p2"), testDir("api", "p1", "p2"))
+	cfg.Group = true
+	plan := NewPlan(cfg, cfg.Directories)
+	results := launchGrouped(context.Background(), cfg, plan, nil, g)
+
+	// web falls back to separate windows, which fail with the bogus terminal;
+	// api still groups.
+	if got := statuses(results); got != "failed,failed,launched,launched" {
+		t.Fatalf("statuses: %s", got)
+	}
+	if !strings.Contains(results[0].Err.Error(), "gopener-no-such-terminal not found") {
+		t.Errorf("fallback error: got %v", results[0].Err)
+	}
+	if want := []string{"win-api:Shell"}; !reflect.DeepEqual(g.tabs, want) {
+		t.Errorf("tabs: got %q, want %q", g.tabs, want)
+	}
+}
+
+func TestLaunchGroupedWindowWithoutTabs(t *testing.T) {
+	g := &fakeGrouper{noTabs: true}
+	cfg := testConfig("gopener-no-such-terminal", testDir("web", "p1", "p2"), testDir("api", "p1", "p2"))
+	cfg.Group = true
+	plan := NewPlan(cfg, cfg.Directories)
+	results := launchGrouped(context.Background(), cfg, plan, nil, g)
+	// The first profile opened; the second falls back to its own window.
+	if got := statuses(results); got != "launched,failed,launched,failed" {
+		t.Fatalf("statuses: %s", got)
+	}
+	if len(g.tabs) != 0 {
+		t.Errorf("no tabs expected, got %q", g.tabs)
+	}
+}
+
+func TestGroupArgvPreview(t *testing.T) {
+	cfg := testConfig("kitty", testDir("web", "p1", "p2"))
+	cfg.Group = true
+	plan := NewPlan(cfg, cfg.Directories)
+	if got := plan.Commands[0].Argv; !slices.Contains(got, "--listen-on") {
+		t.Errorf("first command should open a window: %q", got)
+	}
+	if got := plan.Commands[1].Argv; !slices.Contains(got, "--type=tab") || !slices.Contains(got, "web · Shell") {
+		t.Errorf("second command should open a tab: %q", got)
+	}
+
+	cfg.Group = false
+	plan = NewPlan(cfg, cfg.Directories)
+	if got, want := plan.Commands[1].Argv, buildArgv("kitty", cfg.Directories[0], cfg.Profiles[1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("ungrouped: got %q, want %q", got, want)
+	}
+}
+
+func TestKittyGrouper(t *testing.T) {
+	t.Setenv("SHELL", "bash")
+	sock := filepath.Join(t.TempDir(), "kitty.sock")
+	var started, ran [][]string
+	k := &kittyGrouper{
+		start: func(_ Command, argv []string) error {
+			started = append(started, argv)
+			return os.WriteFile(sock, nil, 0600) // kitty creating its socket
+		},
+		run: func(argv ...string) (string, error) {
+			ran = append(ran, argv)
+			return "", nil
+		},
+		socket:  func() (string, error) { return sock, nil },
+		timeout: time.Second,
+	}
+	dir := config.DirConfig{Path: "/src/web", Name: "web"}
+
+	h, err := k.window(Command{Dir: dir, Profile: config.Profile{Label: "Claude", Cmd: "claude"}})
+	if err != nil || h != sock {
+		t.Fatalf("window: got %q, %v", h, err)
+	}
+	if err := k.tab(h, Command{Dir: dir, Profile: config.Profile{Label: "Shell", Cmd: "bash"}}); err != nil {
+		t.Fatalf("tab: %v", err)
+	}
+	if len(started) != 1 || !slices.Contains(started[0], "unix:"+sock) {
+		t.Errorf("started: %q", started)
+	}
+	want := []string{"kitty", "@", "--to", "unix:" + sock, "launch", "--type=tab",
+		"--tab-title", "web · Shell", "--cwd", "/src/web", "bash", "-c", "bash"}
+	if len(ran) != 1 || !reflect.DeepEqual(ran[0], want) {
+		t.Errorf("tab argv:\n got %q\nwant %q", ran, want)
+	}
+}
+
+func TestKittyGrouperSocketTimeout(t *testing.T) {
+	k := &kittyGrouper{
+		start:   func(Command, []string) error { return nil },
+		socket:  func() (string, error) { return filepath.Join(t.TempDir(), "never"), nil },
+		timeout: 10 * time.Millisecond,
+	}
+	h, err := k.window(Command{Profile: config.Profile{Label: "Claude"}})
+	if err != nil || h != "" {
+		t.Errorf("got %q, %v; want an opened window without a handle", h, err)
+	}
+}
+
+func TestKittySocketIsNew(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", xdg)
+	stale := filepath.Join(xdg, "gopener", "kitty-old")
+	if err := os.MkdirAll(stale, 0700); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleEnvFile)
+	os.Chtimes(stale, old, old)
+
+	a, err := kittySocket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := kittySocket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("two windows share socket %s", a)
+	}
+	if _, err := os.Stat(a); err == nil {
+		t.Errorf("socket %s exists before its window", a)
+	}
+	if filepath.Dir(filepath.Dir(a)) != filepath.Join(xdg, "gopener") {
+		t.Errorf("socket %s is not in gopener's runtime directory", a)
+	}
+	if _, err := os.Stat(stale); err == nil {
+		t.Error("a stale socket directory was kept")
+	}
+}
+
+func TestWeztermGrouper(t *testing.T) {
+	var calls [][]string
+	w := &weztermGrouper{output: func(argv ...string) (string, error) {
+		calls = append(calls, argv)
+		if slices.Contains(argv, "spawn") {
+			return "7", nil
+		}
+		return "", errors.New("set-tab-title: unknown subcommand")
+	}}
+	dir := config.DirConfig{Path: "/src/web", Name: "web"}
+
+	h, err := w.window(Command{Dir: dir, Profile: config.Profile{Label: "Claude", Cmd: "claude"}})
+	if err != nil || h != "7" {
+		t.Fatalf("window: got %q, %v (a failed title must not fail the launch)", h, err)
+	}
+	if err := w.tab(h, Command{Dir: dir, Profile: config.Profile{Label: "Shell", Cmd: "bash"}}); err != nil {
+		t.Fatalf("tab: %v", err)
+	}
+	if !slices.Contains(calls[2], "--pane-id") || !slices.Contains(calls[2], "7") {
+		t.Errorf("tab spawn: got %q", calls[2])
+	}
+}
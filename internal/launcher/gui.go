@@ -0,0 +1,69 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// guiArgv is the argv starting a GUI profile: its command run by p's shell,
+// non-interactively since there is no terminal, and without an exit policy
+// since there is no window to keep.
+func guiArgv(p config.Profile) []string {
+	sh := profileShell(p)
+	sh.Interactive = false
+	p.Shell = &sh
+	p.OnExit = ""
+	return shellArgv(p, p.Cmd)
+}
+
+// withGUI starts GUI commands detached and hands the others to start, so
+// every backend runs them the same way.
+func withGUI(start func(Command) error) func(Command) error {
+	return func(c Command) error {
+		if c.GUI() {
+			return startGUI(c)
+		}
+		return start(c)
+	}
+}
+
+// startGUI spawns c in its directory, so it outlives gopener. Output
+// goes to the log at guiLogPath.
+func startGUI(c Command) error {
+	path, err := guiLogPath(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	log, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+	fmt.Fprintf(log, "# %s %s\n", time.Now().Format(time.RFC3339), JoinArgv(c.Argv))
+
+	cmd := exec.Command(c.Argv[0], c.Argv[1:]...)
+	cmd.Dir = c.Dir.Path
+	cmd.Stdout = log
+	cmd.Stderr = log
+	return spawn(c, cmd)
+}
+
+// guiLogPath is the log of a GUI profile in a directory, under
+// config.StateDir.
+func guiLogPath(c Command) (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	name := strings.NewReplacer("/", "_", string(filepath.Separator), "_", " ", "_").Replace(c.Dir.Name + "-" + c.Profile.Label)
+	return filepath.Join(dir, "logs", name+".log"), nil
+}
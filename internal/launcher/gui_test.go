@@ -0,0 +1,88 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestNewPlanGUI(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	cfg := planFixture()
+	cfg.Terminal = TerminalTmux
+	cfg.Shell = config.Shell{Interactive: true}
+	cfg.Profiles = append(cfg.Profiles, config.Profile{ID: "code", Label: "Code", Cmd: "code .", Kind: config.KindGUI, OnExit: config.ExitHold})
+	cfg.Directories[1].ProfileIDs = []string{"code", "p1"}
+
+	plan := NewPlan(cfg, cfg.Directories)
+	if len(plan.Commands) != 2 {
+		t.Fatalf("expected 2 commands, got %+v", plan.Commands)
+	}
+
+	gui := plan.Commands[0]
+	if !gui.GUI() || gui.Terminal != "" {
+		t.Errorf("GUI command: got GUI %v, terminal %q", gui.GUI(), gui.Terminal)
+	}
+	// No terminal, so neither interactive nor holding.
+	if want := []string{"/bin/bash", "-c", "code ."}; !reflect.DeepEqual(gui.Argv, want) {
+		t.Errorf("GUI argv: got %q, want %q", gui.Argv, want)
+	}
+
+	// The terminal profile is still the first of its directory, so it
+	// creates the tmux session.
+	if term := plan.Commands[1]; term.GUI() || term.Argv[1] != "new-session" {
+		t.Errorf("terminal command: got %q", term.Argv)
+	}
+}
+
+func TestZellijLayoutSkipsGUI(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	cfg.Profiles[1].Kind = config.KindGUI
+	got := zellijLayout(zellijPlan(t, cfg))
+	if strings.Contains(got, `name="Shell"`) {
+		t.Errorf("GUI profile in layout:\n%s", got)
+	}
+}
+
+func TestStartGUI(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	dir := t.TempDir()
+	c := Command{
+		Dir:     config.DirConfig{Path: dir, Name: "web"},
+		Profile: config.Profile{Label: "My App", Kind: config.KindGUI},
+		Argv:    []string{"sh", "-c", "pwd; echo oops >&2"},
+	}
+
+	ran := false
+	start := withGUI(func(Command) error {
+		ran = true
+		return nil
+	})
+	if err := start(c); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if ran {
+		t.Error("GUI command was handed to the terminal starter")
+	}
+
+	log := filepath.Join(state, "gopener", "logs", "web-My_App.log")
+	var out string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		data, _ := os.ReadFile(log)
+		if out = string(data); strings.Contains(out, "\noops\n") {
+			break
+		}
+	}
+	if !strings.Contains(out, dir+"\n") || !strings.Contains(out, "\noops\n") {
+		t.Errorf("log should hold the directory and stderr, got %q", out)
+	}
+	if !strings.HasPrefix(out, "# ") {
+		t.Errorf("log should start with a header, got %q", out)
+	}
+}
@@ -1,8 +1,101 @@
 package launcher
 
-import "github.com/jimbo/gopener/internal/config"
+import (
+	"context"
+	"fmt"
 
-// Launcher opens terminal windows for the given directories.
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// Launcher opens terminal windows for the given directories. It reports one
+// Result per (directory, profile) pair instead of stopping at the first
+// failure, so one broken entry never prevents the others from launching.
+// Cancelling ctx stops before the next item; items not yet started are
+// reported as skipped.
 type Launcher interface {
-	Launch(dirs []config.DirConfig, profiles []config.Profile, terminal string) error
+	Launch(ctx context.Context, req Request) []Result
+}
+
+// Request describes a launch.
+type Request struct {
+	// Config supplies the profiles and launch settings such as the terminal.
+	Config *config.Config
+	// Dirs are the directories to launch; only enabled ones are used. They
+	// usually come from Config.Directories but may be a filtered copy.
+	Dirs []config.DirConfig
+	// Progress, when set, is called just before each command starts.
+	Progress func(Progress)
+}
+
+// Progress reports that the Index-th of Total commands is about to start.
+// Index is 1-based.
+type Progress struct {
+	Index   int
+	Total   int
+	Command Command
+}
+
+func (p Progress) String() string {
+	return fmt.Sprintf("%d/%d: %s → %s", p.Index, p.Total, p.Command.Dir.Name, p.Command.Profile.Label)
+}
+
+// Command is a single resolved launch: one profile opened for one directory,
+// together with the exact argv that starts it.
+type Command struct {
+	Dir      config.DirConfig
+	Profile  config.Profile
+	Terminal string // empty for GUI profiles, which start without one
+	Argv     []string
+}
+
+// GUI reports whether c starts a GUI application instead of a terminal.
+func (c Command) GUI() bool {
+	return c.Profile.IsGUI()
+}
+
+// launchPlan plans the launch, resolving secrets, and starts each command
+// without waiting for it to exit, carrying on past failures. Commands go to
+// their terminal's backend one terminal at a time.
+func launchPlan(ctx context.Context, req Request) []Result {
+	plan := newPlan(req.Config, req.Dirs, newSecrets(ctx))
+	var results []Result
+	for _, part := range plan.byTerminal() {
+		results = append(results, launchTerminal(ctx, req, part)...)
+	}
+	removeEnvFiles(plan.Commands, results)
+	return results
+}
+
+// launchTerminal starts a plan whose commands all use plan.Terminal.
+func launchTerminal(ctx context.Context, req Request, plan Plan) []Result {
+	switch plan.Terminal {
+	case TerminalTmux:
+		return newTmuxBackend(req.Config.Tmux, execTmux).launch(ctx, plan, req.Progress)
+	case TerminalZellij:
+		b := newZellijBackend(req.Config.Zellij, execZellij)
+		b.hostArgv = func(host string, dir config.DirConfig, p config.Profile) []string {
+			return windowArgv(req.Config, host, dir, p)
+		}
+		return b.launch(ctx, plan, req.Progress)
+	}
+	if g := groupFor(req.Config, plan.Terminal); req.Config.Group && g != nil {
+		return launchGrouped(ctx, req.Config, plan, req.Progress, g)
+	}
+	return plan.run(ctx, req.Progress, withGUI(func(c Command) error {
+		return startArgv(c, c.Argv)
+	}))
+}
+
+// dirCommand is the command line running p in dir, quoted for p's shell.
+func dirCommand(dir config.DirConfig, p config.Profile) string {
+	return cdCommand(profileShell(p).Path, dir.Path, p.Cmd)
+}
+
+// profileIndex builds a profile map for quick lookup by ID.
+func profileIndex(profiles []config.Profile) map[string]config.Profile {
+	m := make(map[string]config.Profile, len(profiles))
+	for _, p := range profiles {
+		m[p.ID] = p
+	}
+	return m
 }
@@ -3,8 +3,8 @@
 package launcher
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/jimbo/gopener/internal/config"
@@ -24,81 +24,90 @@ func escapeAppleScript(s string) string {
 	return s
 }
 
-func (l *darwinLauncher) Launch(dirs []config.DirConfig, profiles []config.Profile, terminal string) error {
-	// Default to Terminal.app if not specified
+func (l *darwinLauncher) Launch(ctx context.Context, req Request) []Result {
+	return launchPlan(ctx, req)
+}
+
+// resolveTerminal defaults to Terminal.app when none is configured.
+func resolveTerminal(terminal string) (string, error) {
 	if terminal == "" {
-		terminal = "Terminal"
+		return "Terminal", nil
 	}
+	return terminal, nil
+}
 
-	// Build profile map for quick lookup.
-	profileMap := make(map[string]config.Profile, len(profiles))
-	for _, p := range profiles {
-		profileMap[p.ID] = p
+// typedCommand is the line typed into a new session of a scripted terminal,
+// which runs the user's login shell rather than p's. None of these take a
+// title, so the line sets it first. That shell stays once the command
+// exits under the shell exit policy, the default here; close and hold end
+// it. Typed lines end up in the shell's history, so secrets are only ever
+// sourced from the profile's EnvFile, never typed.
+func typedCommand(dir config.DirConfig, p config.Profile) string {
+	sh := config.DefaultShell()
+	p.Shell = &config.Shell{Path: sh}
+	line := titleCommand(p, windowTitle(dir, p)) + "\n" + envCommand(p) + cdCommand(sh, dir.Path, wrapCommand(p, sh, p.Cmd))
+	if p.ExitPolicy() == config.ExitShell {
+		return line
 	}
+	return exitCommand(p, line, false) + "\nexit"
+}
 
-	for _, dir := range dirs {
-		if !dir.Enabled {
-			continue
-		}
-		for _, pid := range dir.ProfileIDs {
-			p, ok := profileMap[pid]
-			if !ok {
-				continue
-			}
-			// Escape the path and command for AppleScript
-			escapedPath := escapeAppleScript(dir.Path)
-			escapedCmd := escapeAppleScript(p.Cmd)
-
-			var cmd *exec.Cmd
-			switch terminal {
-			case "Ghostty":
-				// Ghostty on macOS: Use the binary from the app bundle
-				// LIMITATION: Ghostty currently creates separate windows/dock entries
-				// for each launch. There's no API to open tabs in an existing instance.
-				// Recommendation: Use iTerm or Terminal.app for single-instance behavior.
-				shellCmd := fmt.Sprintf("cd \"%s\" && exec %s", escapedPath, escapedCmd)
-				ghosttyBinary := "/Applications/Ghostty.app/Contents/MacOS/ghostty"
-				cmd = exec.Command(ghosttyBinary, "-e", "sh", "-c", shellCmd)
-			case "iTerm":
-				// iTerm2 has a different AppleScript API
-				script := fmt.Sprintf(
-					`tell application "iTerm"
-						create window with default profile
-						tell current session of current window
-							write text "cd \"%s\" && %s"
-						end tell
-					end tell`,
-					escapedPath, escapedCmd,
-				)
-				cmd = exec.Command("osascript", "-e", script)
-			case "Warp":
-				// Warp uses System Events for keyboard automation
-				script := fmt.Sprintf(
-					`tell application "Warp" to activate
-					tell application "System Events"
-						tell process "Warp"
-							keystroke "t" using {command down}
-							delay 0.5
-							keystroke "cd \"%s\" && %s"
-							keystroke return
-						end tell
-					end tell`,
-					escapedPath, escapedCmd,
-				)
-				cmd = exec.Command("osascript", "-e", script)
-			default:
-				// Terminal.app and other terminals use standard AppleScript
-				script := fmt.Sprintf(
-					`tell application "%s" to do script "cd \"%s\" && %s"`,
-					terminal, escapedPath, escapedCmd,
-				)
-				cmd = exec.Command("osascript", "-e", script)
-			}
+func buildArgv(terminal string, dir config.DirConfig, p config.Profile) []string {
+	// Escape the command line for AppleScript
+	escapedCmd := escapeAppleScript(typedCommand(dir, p))
 
-			if err := cmd.Start(); err != nil {
-				return fmt.Errorf("launching %s for %s: %w", p.Label, dir.Name, err)
-			}
+	switch terminal {
+	case "Ghostty":
+		// Ghostty on macOS: Use the binary from the app bundle
+		// LIMITATION: Ghostty currently creates separate windows/dock entries
+		// for each launch. There's no API to open tabs in an existing instance.
+		// Recommendation: Use iTerm or Terminal.app for single-instance behavior.
+		ghosttyBinary := "/Applications/Ghostty.app/Contents/MacOS/ghostty"
+		title := "--title=" + windowTitle(dir, p)
+		shell := profileShell(p).Path
+		switch p.ExitPolicy() {
+		case config.ExitHold:
+			// Ghostty waits for a key itself once the command exits.
+			shellCmd := cdCommand(shell, dir.Path, p.Cmd)
+			return append([]string{ghosttyBinary, title, "--wait-after-command=true", "-e"}, heldShellArgv(p, shellCmd)...)
+		case config.ExitShell:
+			return append([]string{ghosttyBinary, title, "-e"}, shellArgv(p, cdCommand(shell, dir.Path, p.Cmd))...)
 		}
+		shellCmd := cdCommand(shell, dir.Path, "exec "+p.Cmd)
+		return append([]string{ghosttyBinary, title, "-e"}, shellArgv(p, shellCmd)...)
+	case "iTerm":
+		// iTerm2 has a different AppleScript API
+		script := fmt.Sprintf(
+			`tell application "iTerm"
+				create window with default profile
+				tell current session of current window
+					write text "%s"
+				end tell
+			end tell`,
+			escapedCmd,
+		)
+		return []string{"osascript", "-e", script}
+	case "Warp":
+		// Warp uses System Events for keyboard automation
+		script := fmt.Sprintf(
+			`tell application "Warp" to activate
+			tell application "System Events"
+				tell process "Warp"
+					keystroke "t" using {command down}
+					delay 0.5
+					keystroke "%s"
+					keystroke return
+				end tell
+			end tell`,
+			escapedCmd,
+		)
+		return []string{"osascript", "-e", script}
+	default:
+		// Terminal.app and other terminals use standard AppleScript
+		script := fmt.Sprintf(
+			`tell application "%s" to do script "%s"`,
+			terminal, escapedCmd,
+		)
+		return []string{"osascript", "-e", script}
 	}
-	return nil
 }
@@ -3,11 +3,11 @@
 package launcher
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 
 	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/terminal"
 )
 
 type linuxLauncher struct{}
@@ -16,75 +16,48 @@ func New() Launcher {
 	return &linuxLauncher{}
 }
 
-func (l *linuxLauncher) Launch(dirs []config.DirConfig, profiles []config.Profile, terminal string) error {
-	term := terminal
-	if term == "" {
-		term = detectTerminal()
-	}
-	if term == "" {
-		return fmt.Errorf("no supported terminal emulator found")
-	}
+func (l *linuxLauncher) Launch(ctx context.Context, req Request) []Result {
+	return launchPlan(ctx, req)
+}
 
-	// Build profile map for quick lookup.
-	profileMap := make(map[string]config.Profile, len(profiles))
-	for _, p := range profiles {
-		profileMap[p.ID] = p
-	}
+// detectTerminal is terminal.Detect, replaced in tests.
+var detectTerminal = terminal.Detect
 
-	for _, dir := range dirs {
-		if !dir.Enabled {
-			continue
-		}
-		for _, pid := range dir.ProfileIDs {
-			p, ok := profileMap[pid]
-			if !ok {
-				continue
-			}
-			shellCmd := fmt.Sprintf("cd %q && %s", dir.Path, p.Cmd)
-			cmd := buildCmd(term, shellCmd)
-			if cmd == nil {
-				continue
-			}
-			if err := cmd.Start(); err != nil {
-				return fmt.Errorf("launching %s for %s: %w", p.Label, dir.Name, err)
-			}
-		}
+// resolveTerminal falls back to the first installed terminal when none is
+// configured.
+func resolveTerminal(term string) (string, error) {
+	if term != "" {
+		return term, nil
+	}
+	if term := detectTerminal(); term != "" {
+		return term, nil
 	}
-	return nil
+	return "", fmt.Errorf("no supported terminal emulator found")
 }
 
-func detectTerminal() string {
-	if t := os.Getenv("TERMINAL"); t != "" {
-		if path, err := exec.LookPath(t); err == nil && path != "" {
-			return t
-		}
+// buildArgv opens p in dir with the registry's entry for term, guessing the
+// common "-e" form for unknown terminals. Terminals without a working
+// directory flag change directory in the shell instead, those without a
+// title flag set it with an escape sequence, and those with a hold flag
+// keep the window open for the hold exit policy.
+func buildArgv(term string, dir config.DirConfig, p config.Profile) []string {
+	t, ok := terminal.Lookup(term)
+	if !ok {
+		t = terminal.Generic(term)
 	}
-	candidates := []string{"ghostty", "wezterm", "kitty", "alacritty", "konsole", "gnome-terminal", "xterm"}
-	for _, c := range candidates {
-		if _, err := exec.LookPath(c); err == nil {
-			return c
-		}
+	if t.NoCommand {
+		return nil
 	}
-	return ""
-}
-
-func buildCmd(term, shellCmd string) *exec.Cmd {
-	switch term {
-	case "ghostty":
-		return exec.Command("ghostty", "-e", "bash", "-c", shellCmd)
-	case "wezterm":
-		return exec.Command("wezterm", "start", "--", "bash", "-c", shellCmd)
-	case "kitty":
-		return exec.Command("kitty", "bash", "-c", shellCmd)
-	case "alacritty":
-		return exec.Command("alacritty", "-e", "bash", "-c", shellCmd)
-	case "konsole":
-		return exec.Command("konsole", "-e", "bash", "-c", shellCmd)
-	case "gnome-terminal":
-		return exec.Command("gnome-terminal", "--", "bash", "-c", shellCmd)
-	case "xterm":
-		return exec.Command("xterm", "-e", "bash", "-c", shellCmd)
-	default:
-		return exec.Command(term, "-e", "bash", "-c", shellCmd)
+	shellCmd := p.Cmd
+	if t.DirFlag == "" {
+		shellCmd = dirCommand(dir, p)
+	}
+	title := windowTitle(dir, p)
+	if t.TitleFlag == "" {
+		shellCmd = titleCommand(p, title) + "\n" + shellCmd
+	}
+	if p.ExitPolicy() == config.ExitHold && t.HoldFlag != "" {
+		return t.HeldArgv(dir.Path, title, heldShellArgv(p, shellCmd))
 	}
+	return t.Argv(dir.Path, title, shellArgv(p, shellCmd))
 }
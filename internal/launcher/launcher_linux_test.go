@@ -0,0 +1,126 @@
+//go:build linux
+
+package launcher
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/terminal"
+)
+
+func TestBuildArgv(t *testing.T) {
+	t.Setenv("SHELL", "bash")
+	dir := config.DirConfig{Path: "/src/web", Name: "web"}
+	p := config.Profile{ID: "p1", Label: "Claude", Cmd: "claude"}
+	const title = "web · Claude"
+	// Terminals without a title flag get an escape sequence instead.
+	const osc = `printf '\033]0;%s\007' 'web · Claude'` + "\n"
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"ghostty", []string{"ghostty", "--working-directory=/src/web", "--title=" + title, "-e", "bash", "-c", "claude"}},
+		{"wezterm", []string{"wezterm", "start", "--cwd", "/src/web", "--", "bash", "-c", osc + "claude"}},
+		{"kitty", []string{"kitty", "--directory", "/src/web", "--title", title, "bash", "-c", "claude"}},
+		{"gnome-terminal", []string{"gnome-terminal", "--working-directory=/src/web", "--", "bash", "-c", osc + "claude"}},
+		// No working directory flag: the shell changes directory instead.
+		{"xterm", []string{"xterm", "-T", title, "-e", "bash", "-c", "cd /src/web && claude"}},
+		// Unknown terminals get the common "-e" form.
+		{"foot", []string{"foot", "-e", "bash", "-c", osc + "cd /src/web && claude"}},
+		// Known but unable to run commands.
+		{"warp-terminal", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			if got := buildArgv(tt.term, dir, p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildArgv(%q):\n got %q\nwant %q", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildArgvHold(t *testing.T) {
+	t.Setenv("SHELL", "bash")
+	dir := config.DirConfig{Path: "/src/web", Name: "web"}
+	p := config.Profile{ID: "p1", Label: "Claude", Cmd: "claude", OnExit: config.ExitHold}
+
+	// kitty keeps the window open itself, so the command only reports the
+	// exit status.
+	got := buildArgv("kitty", dir, p)
+	want := append([]string{"kitty", "--hold", "--directory", "/src/web", "--title", "web · Claude"}, heldShellArgv(p, "claude")...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("kitty:\n got %q\nwant %q", got, want)
+	}
+
+	// gnome-terminal has no hold flag: the command waits for a key.
+	got = buildArgv("gnome-terminal", dir, p)
+	want = append([]string{"gnome-terminal", "--working-directory=/src/web", "--"}, shellArgv(p, titleCommand(p, "web · Claude")+"\nclaude")...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gnome-terminal:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestLaunchPlanReportsEveryItem(t *testing.T) {
+	cfg := planFixture()
+	cfg.Terminal = "gopener-no-such-terminal"
+	// A terminal that cannot exist makes every start fail, without
+	// stopping the remaining items.
+	results := launchPlan(context.Background(), Request{Config: cfg, Dirs: cfg.Directories})
+
+	var failed, skipped int
+	for _, r := range results {
+		switch r.Status {
+		case StatusFailed:
+			failed++
+			if r.Err == nil || !errors.Is(r.Err, exec.ErrNotFound) {
+				t.Errorf("expected not-found error, got %v", r.Err)
+			}
+		case StatusSkipped:
+			skipped++
+		default:
+			t.Errorf("unexpected result %v", r)
+		}
+	}
+	if failed != 2 || skipped != 2 {
+		t.Errorf("got %d failed, %d skipped; want 2 and 2", failed, skipped)
+	}
+}
+
+// TestNewPlanDetectsTerminalLazily checks that a failed detection only
+// fails the commands that need the global terminal.
+func TestNewPlanDetectsTerminalLazily(t *testing.T) {
+	detected := 0
+	detectTerminal = func() string { detected++; return "" }
+	t.Cleanup(func() { detectTerminal = terminal.Detect })
+
+	cfg := planFixture()
+	cfg.Terminal = ""
+	cfg.Profiles[0].Kind = config.KindGUI
+	cfg.Profiles[1].Terminal = "kitty"
+	plan := NewPlan(cfg, cfg.Directories)
+	if len(plan.Commands) != 2 || detected != 0 || plan.Terminal != "" {
+		t.Fatalf("no command needs detecting: commands %+v, detected %d times, terminal %q", plan.Commands, detected, plan.Terminal)
+	}
+
+	cfg.Profiles[1].Terminal = ""
+	cfg.Directories[2].ProfileIDs = []string{"p2"}
+	plan = NewPlan(cfg, cfg.Directories)
+	if len(plan.Commands) != 1 || !plan.Commands[0].GUI() || detected != 1 {
+		t.Fatalf("commands %+v, detected %d times", plan.Commands, detected)
+	}
+	var failed []string
+	for _, r := range plan.skipped() {
+		if r.Status == StatusFailed {
+			failed = append(failed, r.Dir.Name+"/"+r.ProfileID+": "+r.Err.Error())
+		}
+	}
+	want := []string{"web/p2: profile Shell: no supported terminal emulator found", "api/p2: profile Shell: no supported terminal emulator found"}
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("failed:\n got %q\nwant %q", failed, want)
+	}
+}
@@ -0,0 +1,258 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// Plan is everything a launch would do: the resolved commands plus warnings
+// about configuration that will be skipped. Launchers execute plans built by
+// NewPlan, so a plan shown to the user always matches what gets launched.
+type Plan struct {
+	Terminal string // global terminal or multiplexer, configured or detected
+	Commands []Command
+	Warnings []Warning
+
+	// Set on the parts returned by byTerminal so progress counts across
+	// the whole launch.
+	offset, total int
+}
+
+// Warning describes an enabled directory, or one of its profile IDs, that
+// cannot be launched.
+type Warning struct {
+	Dir       config.DirConfig
+	ProfileID string // empty when the warning is about the directory itself
+	Message   string
+
+	// Err is set when the launch failed rather than skipped the item, such
+	// as for a secret that could not be read; Message is its text.
+	Err error
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Dir.Name, w.Message)
+}
+
+// NewPlan resolves every enabled directory × assigned profile pair in dirs
+// into the argv that a launch with cfg's settings would execute, without
+// starting anything. Each command's profile carries what was resolved for
+// its directory; pairs that cannot be launched become warnings.
+func NewPlan(cfg *config.Config, dirs []config.DirConfig) Plan {
+	return newPlan(cfg, dirs, nil)
+}
+
+// envFilePlaceholder is the EnvFile of planned profiles that need one. The
+// secret references and .env values are moved out of Env either way, so a
+// plan never shows a value; only a launch resolves the references and
+// writes them, with the .env values, to a real env file.
+const envFilePlaceholder = "<env-file>"
+
+// newPlan is NewPlan, writing the secrets of each profile to a real EnvFile
+// with s when s is not nil, along with the values from the .env file. A
+// profile whose secrets cannot be read gets a warning that fails it.
+func newPlan(cfg *config.Config, dirs []config.DirConfig, s *secrets) Plan {
+	// Overrides from Config.TerminalFor name a terminal, so only the global
+	// one may need detecting, and only once a command without one needs
+	// it. When detection fails, those commands fail.
+	var term string
+	var termErr error
+	resolved := false
+	globalTerminal := func() (string, error) {
+		if !resolved {
+			term, termErr = resolveTerminal(cfg.Terminal)
+			resolved = true
+		}
+		return term, termErr
+	}
+
+	profileMap := profileIndex(cfg.Profiles)
+
+	var plan Plan
+	for _, dir := range dirs {
+		if !dir.Enabled {
+			continue
+		}
+		if len(dir.ProfileIDs) == 0 {
+			plan.Warnings = append(plan.Warnings, Warning{Dir: dir, Message: "enabled but has no profiles"})
+			continue
+		}
+		dotenv, wrap, err := activation(dir)
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, Warning{Dir: dir, Message: err.Error()})
+			continue
+		}
+		n := make(map[string]int) // profiles planned so far for this directory, by terminal
+		for _, pid := range dir.ProfileIDs {
+			p, ok := profileMap[pid]
+			if !ok {
+				plan.Warnings = append(plan.Warnings, Warning{
+					Dir:       dir,
+					ProfileID: pid,
+					Message:   fmt.Sprintf("profile %s no longer exists", pid),
+				})
+				continue
+			}
+			sh := cfg.ShellFor(p)
+			p.Shell = &sh
+			cmd, err := profileCmd(cfg, dir, p)
+			var env map[string]string
+			if err == nil {
+				env, err = cfg.EnvFor(withDotenv(dir, dotenv), p)
+			}
+			var pwrap []string
+			if err == nil {
+				pwrap, err = profileWrap(cfg, dir, p)
+			}
+			if err != nil {
+				plan.Warnings = append(plan.Warnings, Warning{
+					Dir:       dir,
+					ProfileID: pid,
+					Message:   fmt.Sprintf("profile %s: %v", p.Label, err),
+				})
+				continue
+			}
+			t := cfg.TerminalFor(dir, p)
+			if t == "" && !p.IsGUI() {
+				if t, err = globalTerminal(); err != nil {
+					err = fmt.Errorf("profile %s: %w", p.Label, err)
+					plan.Warnings = append(plan.Warnings, Warning{Dir: dir, ProfileID: pid, Message: err.Error(), Err: err})
+					continue
+				}
+			}
+			env, refs := splitSecrets(dir, dotenv, p, env)
+			env, values := splitDotenv(dir, dotenv, env)
+			var file string
+			if len(refs) > 0 || len(values) > 0 {
+				file = envFilePlaceholder
+				if s != nil {
+					file, err = s.envFile(dir, refs, values)
+				}
+				if err != nil {
+					err = fmt.Errorf("profile %s: %w", p.Label, err)
+					plan.Warnings = append(plan.Warnings, Warning{Dir: dir, ProfileID: pid, Message: err.Error(), Err: err})
+					continue
+				}
+			}
+			p.Cmd, p.Env, p.Secrets, p.EnvFile, p.Wrap = cmd, env, hideDotenv(refs, values), file, append(pwrap, wrap...)
+			if p.IsGUI() {
+				// Started on their own, so they do not count as one of the
+				// directory's terminal profiles.
+				plan.Commands = append(plan.Commands, Command{Dir: dir, Profile: p, Argv: guiArgv(p)})
+				continue
+			}
+			var argv []string
+			switch t {
+			case TerminalTmux:
+				argv = tmuxArgv(cfg.Tmux, dir, p, n[t])
+			case TerminalZellij:
+				argv = zellijArgv(cfg.Zellij)
+			default:
+				if cfg.Group {
+					argv = groupArgv(cfg, t, dir, p, n[t])
+				}
+				if argv == nil {
+					argv = windowArgv(cfg, t, dir, p)
+				}
+			}
+			if argv == nil {
+				plan.Warnings = append(plan.Warnings, Warning{
+					Dir:       dir,
+					ProfileID: pid,
+					Message:   fmt.Sprintf("terminal %s is not supported", t),
+				})
+				continue
+			}
+			plan.Commands = append(plan.Commands, Command{Dir: dir, Profile: p, Terminal: t, Argv: argv})
+			n[t]++
+		}
+	}
+	plan.Terminal = cfg.Terminal
+	if plan.Terminal == "" {
+		plan.Terminal = term
+	}
+	return plan
+}
+
+// Filter returns the directories a launch needs to run exactly the commands
+// for which keep returns true. Directories with nothing left are disabled.
+func (p Plan) Filter(dirs []config.DirConfig, keep func(Command) bool) []config.DirConfig {
+	kept := make(map[string][]string)
+	for _, c := range p.Commands {
+		if keep(c) {
+			kept[c.Dir.Path] = append(kept[c.Dir.Path], c.Profile.ID)
+		}
+	}
+
+	out := make([]config.DirConfig, 0, len(dirs))
+	for _, d := range dirs {
+		d.ProfileIDs = kept[d.Path]
+		d.Enabled = len(d.ProfileIDs) > 0
+		out = append(out, d)
+	}
+	return out
+}
+
+// byTerminal splits p into one plan per terminal, in order of first use, so
+// each can be handed to its backend. GUI commands form a plan with no
+// terminal. The first plan carries the warnings.
+func (p Plan) byTerminal() []Plan {
+	if len(p.Commands) == 0 {
+		return []Plan{p}
+	}
+	var parts []Plan
+	index := make(map[string]int)
+	for _, c := range p.Commands {
+		i, ok := index[c.Terminal]
+		if !ok {
+			i = len(parts)
+			index[c.Terminal] = i
+			parts = append(parts, Plan{Terminal: c.Terminal, total: len(p.Commands)})
+		}
+		parts[i].Commands = append(parts[i].Commands, c)
+	}
+	parts[0].Warnings = p.Warnings
+	offset := 0
+	for i := range parts {
+		parts[i].offset = offset
+		offset += len(parts[i].Commands)
+	}
+	return parts
+}
+
+// run hands every command to start in order, reporting progress before each
+// one. Commands not reached before ctx is cancelled are reported as skipped.
+func (p Plan) run(ctx context.Context, progress func(Progress), start func(Command) error) []Result {
+	total := p.total
+	if total == 0 {
+		total = len(p.Commands)
+	}
+	results := make([]Result, 0, len(p.Commands)+len(p.Warnings))
+	for i, c := range p.Commands {
+		r := Result{Dir: c.Dir, ProfileID: c.Profile.ID, Profile: c.Profile, Status: StatusLaunched}
+		if ctx.Err() != nil {
+			r.Status = StatusSkipped
+			r.Reason = "cancelled"
+			results = append(results, r)
+			continue
+		}
+		if progress != nil {
+			progress(Progress{Index: p.offset + i + 1, Total: total, Command: c})
+		}
+		if err := start(c); err != nil {
+			var skip skipError
+			if errors.As(err, &skip) {
+				r.Status = StatusSkipped
+				r.Reason = skip.Error()
+			} else {
+				r.Status = StatusFailed
+				r.Err = describeStartError(err)
+			}
+		}
+		results = append(results, r)
+	}
+	return append(results, p.skipped()...)
+}
@@ -0,0 +1,184 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// testConfig is the configuration the launcher tests start from: profiles
+// p1 (Claude, running claude) and p2 (Shell, running bash) opened in term
+// for dirs.
+func testConfig(term string, dirs ...config.DirConfig) *config.Config {
+	return &config.Config{
+		Terminal: term,
+		Profiles: []config.Profile{
+			{ID: "p1", Label: "Claude", Cmd: "claude"},
+			{ID: "p2", Label: "Shell", Cmd: "bash"},
+		},
+		Wrappers: []config.Wrapper{
+			{Name: "scope", Argv: []string{"systemd-run", "--user", "--scope"}},
+			{Name: "nice", Argv: []string{"nice", "-n", "10"}},
+			{Name: "bwrap", Argv: []string{"bwrap", "--ro-bind", "/", "/", "--bind", "{dir}", "{dir}"}},
+		},
+		Directories: dirs,
+	}
+}
+
+// testDir is the enabled directory /src/name with profileIDs assigned.
+func testDir(name string, profileIDs ...string) config.DirConfig {
+	return config.DirConfig{Path: "/src/" + name, Name: name, Enabled: true, ProfileIDs: profileIDs}
+}
+
+// planFixture plans one disabled directory, one with a dangling profile ID
+// and one without profiles.
+func planFixture() *config.Config {
+	return testConfig("xterm",
+		config.DirConfig{Path: "/src/off", Name: "off", Enabled: false, ProfileIDs: []string{"p1"}},
+		testDir("web", "p1", "gone", "p2"),
+		testDir("api"))
+}
+
+func TestNewPlan(t *testing.T) {
+	cfg := planFixture()
+	plan := NewPlan(cfg, cfg.Directories)
+
+	if len(plan.Commands) != 2 {
+		t.Fatalf("expected 2 commands, got %+v", plan.Commands)
+	}
+	for i, want := range []string{"Claude", "Shell"} {
+		c := plan.Commands[i]
+		if c.Dir.Name != "web" || c.Profile.Label != want || c.Terminal != "xterm" || len(c.Argv) == 0 {
+			t.Errorf("command %d: got %+v", i, c)
+		}
+	}
+
+	if len(plan.Warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %+v", plan.Warnings)
+	}
+	if w := plan.Warnings[0]; w.Dir.Name != "web" || w.ProfileID != "gone" {
+		t.Errorf("warning 0: got %+v", w)
+	}
+	if w := plan.Warnings[1]; w.Dir.Name != "api" || w.ProfileID != "" {
+		t.Errorf("warning 1: got %+v", w)
+	}
+}
+
+func TestPlanFilter(t *testing.T) {
+	cfg := planFixture()
+	dirs := cfg.Directories
+	plan := NewPlan(cfg, dirs)
+
+	filtered := plan.Filter(dirs, func(c Command) bool { return c.Profile.ID == "p2" })
+	if len(filtered) != len(dirs) {
+		t.Fatalf("expected %d dirs, got %d", len(dirs), len(filtered))
+	}
+	for _, d := range filtered {
+		switch d.Name {
+		case "web":
+			if !d.Enabled || len(d.ProfileIDs) != 1 || d.ProfileIDs[0] != "p2" {
+				t.Errorf("web: got %+v", d)
+			}
+		default:
+			if d.Enabled {
+				t.Errorf("%s should be disabled: %+v", d.Name, d)
+			}
+		}
+	}
+
+	// Re-planning the filtered dirs yields exactly the kept command.
+	again := NewPlan(cfg, filtered)
+	if len(again.Commands) != 1 || again.Commands[0].Profile.ID != "p2" || len(again.Warnings) != 0 {
+		t.Errorf("replanned: got %+v", again)
+	}
+
+	// The caller's slice is left untouched.
+	if len(dirs[1].ProfileIDs) != 3 {
+		t.Errorf("Filter modified its input: %+v", dirs[1])
+	}
+}
+
+func TestPlanRunProgressAndCancel(t *testing.T) {
+	cfg := planFixture()
+	plan := NewPlan(cfg, cfg.Directories)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var seen []Progress
+	results := plan.run(ctx, func(p Progress) { seen = append(seen, p) }, func(c Command) error {
+		// Cancel after the first command starts; the second must not run.
+		cancel()
+		return nil
+	})
+
+	if len(seen) != 1 || seen[0].Index != 1 || seen[0].Total != 2 {
+		t.Errorf("progress: got %+v", seen)
+	}
+	if got := seen[0].String(); got != "1/2: web → Claude" {
+		t.Errorf("progress string: got %q", got)
+	}
+	if results[0].Status != StatusLaunched {
+		t.Errorf("result 0: got %v", results[0])
+	}
+	if results[1].Status != StatusSkipped || results[1].Reason != "cancelled" {
+		t.Errorf("result 1: got %v", results[1])
+	}
+}
+
+func TestNewPlanTerminalOverrides(t *testing.T) {
+	cfg := planFixture()
+	cfg.Profiles[0].Terminal = "kitty"
+	cfg.Directories[2] = config.DirConfig{Path: "/src/api", Name: "api", Enabled: true, ProfileIDs: []string{"p1", "p2"}, Terminal: TerminalTmux}
+
+	plan := NewPlan(cfg, cfg.Directories)
+	var got []string
+	for _, c := range plan.Commands {
+		got = append(got, c.Dir.Name+"/"+c.Profile.ID+"="+c.Terminal)
+	}
+	want := []string{"web/p1=kitty", "web/p2=xterm", "api/p1=tmux", "api/p2=tmux"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("terminals: got %q, want %q", got, want)
+	}
+	if plan.Terminal != "xterm" {
+		t.Errorf("plan terminal: got %q, want the global xterm", plan.Terminal)
+	}
+	// Profiles are counted per terminal: the first tmux profile of api
+	// creates its session even though it is not api's first profile overall.
+	if a := plan.Commands[2].Argv; a[1] != "new-session" {
+		t.Errorf("first tmux command: got %q", a)
+	}
+	if a := plan.Commands[3].Argv; a[1] != "new-window" {
+		t.Errorf("second tmux command: got %q", a)
+	}
+}
+
+func TestPlanByTerminal(t *testing.T) {
+	cfg := planFixture()
+	cfg.Profiles[1].Terminal = "kitty"
+	cfg.Directories[0].Enabled = true
+	cfg.Directories[0].ProfileIDs = []string{"p2", "p1"}
+
+	plan := NewPlan(cfg, cfg.Directories)
+	parts := plan.byTerminal()
+	if len(parts) != 2 || parts[0].Terminal != "kitty" || parts[1].Terminal != "xterm" {
+		t.Fatalf("parts: got %+v", parts)
+	}
+
+	var progress []string
+	var results []Result
+	for _, part := range parts {
+		results = append(results, part.run(context.Background(), func(p Progress) {
+			progress = append(progress, fmt.Sprintf("%d/%d %s", p.Index, p.Total, p.Command.Terminal))
+		}, func(Command) error { return nil })...)
+	}
+	want := []string{"1/4 kitty", "2/4 kitty", "3/4 xterm", "4/4 xterm"}
+	if !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress: got %q, want %q", progress, want)
+	}
+	// Four launched, plus the two warnings reported once.
+	if len(results) != 6 {
+		t.Errorf("expected 6 results, got %+v", results)
+	}
+}
@@ -0,0 +1,75 @@
+package launcher
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// quotePOSIX quotes s as one word for sh, bash, zsh and ksh. Words made of
+// safe characters are left alone, unless they start with '=', which zsh
+// expands to the path of the command named by the rest; anything else is
+// single-quoted, which suppresses every expansion, with embedded quotes
+// written as '\”.
+func quotePOSIX(s string) string {
+	if s != "" && s[0] != '=' && isSafeWord(s, "-_./=:,+@%") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish quotes s as one word for fish. Fish single quotes differ from
+// POSIX ones: a backslash escapes a quote or another backslash inside them,
+// so both are escaped. '%' is left out of the safe set because older fish
+// expands it at the start of a word.
+func quoteFish(s string) string {
+	if s != "" && isSafeWord(s, "-_./=:,+@") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func isSafeWord(s, punct string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(punct, r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// quoterFor returns the quoting function for the shell at path. Everything
+// but fish is treated as POSIX.
+func quoterFor(shell string) func(string) string {
+	if filepath.Base(shell) == "fish" {
+		return quoteFish
+	}
+	return quotePOSIX
+}
+
+// joinFor renders argv as one command line for the shell at path.
+func joinFor(shell string, argv []string) string {
+	quote := quoterFor(shell)
+	parts := make([]string, len(argv))
+	for i, a := range argv {
+		parts[i] = quote(a)
+	}
+	return strings.Join(parts, " ")
+}
+
+// cdCommand is the command line changing into dir and then running cmd in
+// the shell at path.
+func cdCommand(shell, dir, cmd string) string {
+	return "cd " + quoterFor(shell)(dir) + " && " + cmd
+}
+
+// profileShell is the shell p runs in: the one NewPlan resolved, or
+// config.DefaultShell for a profile without one.
+func profileShell(p config.Profile) config.Shell {
+	if p.Shell != nil && p.Shell.Path != "" {
+		return *p.Shell
+	}
+	return config.Shell{Path: config.DefaultShell()}
+}
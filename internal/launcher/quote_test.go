@@ -0,0 +1,216 @@
+package launcher
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// trickyNames are directory names a naive quoting gets wrong.
+var trickyNames = []string{
+	"plain",
+	"with space",
+	"$HOME",
+	"${PATH}",
+	"`id`",
+	"$(id)",
+	"it's",
+	`say "hi"`,
+	`back\slash`,
+	`trailing\`,
+	"new\nline",
+	"tab\there",
+	"*?[a]",
+	"~user",
+	"-dash",
+	"=equals",
+	"a;b&&c|d",
+	"%self",
+	"héllo wörld",
+	"日本語",
+	"emoji 🚀",
+	"{a,b}",
+	"!bang",
+	"'",
+	"''",
+}
+
+func TestQuotePOSIX(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "''"},
+		{"/src/web", "/src/web"},
+		{"a b", "'a b'"},
+		{"$HOME", "'$HOME'"},
+		{"it's", `'it'\''s'`},
+		{`a\b`, `'a\b'`},
+		{"héllo", "'héllo'"},
+		{"=foo", "'=foo'"},
+		{"KEY=v", "KEY=v"},
+	}
+	for _, tt := range tests {
+		if got := quotePOSIX(tt.in); got != tt.want {
+			t.Errorf("quotePOSIX(%q): got %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteFish(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "''"},
+		{"/src/web", "/src/web"},
+		{"$HOME", "'$HOME'"},
+		{"it's", `'it\'s'`},
+		{`a\b`, `'a\\b'`},
+		{`trailing\`, `'trailing\\'`},
+		{"%self", "'%self'"},
+	}
+	for _, tt := range tests {
+		if got := quoteFish(tt.in); got != tt.want {
+			t.Errorf("quoteFish(%q): got %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoterFor(t *testing.T) {
+	if got := quoterFor("/usr/bin/fish")(`a\b`); got != `'a\\b'` {
+		t.Errorf("fish: got %s", got)
+	}
+	for _, sh := range []string{"sh", "/bin/bash", "zsh", "dash"} {
+		if got := quoterFor(sh)(`a\b`); got != `'a\b'` {
+			t.Errorf("%s: got %s", sh, got)
+		}
+	}
+}
+
+func TestDirCommandUsesProfileShell(t *testing.T) {
+	dir := config.DirConfig{Path: "/src/it's"}
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"/bin/bash", `cd '/src/it'\''s' && make`},
+		{"/usr/bin/fish", `cd '/src/it\'s' && make`},
+	}
+	for _, tt := range tests {
+		p := config.Profile{Cmd: "make", Shell: &config.Shell{Path: tt.shell}}
+		if got := dirCommand(dir, p); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.shell, got, tt.want)
+		}
+	}
+}
+
+// shellsFor returns the installed shells quoting is checked against.
+func shellsFor(tb testing.TB) []string {
+	tb.Helper()
+	var shells []string
+	for _, name := range []string{"sh", "bash", "dash", "zsh", "fish"} {
+		if path, err := exec.LookPath(name); err == nil {
+			shells = append(shells, path)
+		}
+	}
+	if len(shells) == 0 {
+		tb.Skip("no shell installed")
+	}
+	// zsh expands words the others leave alone, so CI must cover it.
+	if _, err := exec.LookPath("zsh"); err != nil && os.Getenv("CI") != "" {
+		tb.Fatal("zsh is not installed")
+	}
+	return shells
+}
+
+// echoWord has shell print the single word quoted for it.
+func echoWord(shell, s string) (string, error) {
+	out, err := exec.Command(shell, "-c", "printf %s "+quoterFor(shell)(s)).Output()
+	return string(out), err
+}
+
+// TestQuoteRoundTrip checks that quoted words reach the shell unchanged.
+func TestQuoteRoundTrip(t *testing.T) {
+	for _, shell := range shellsFor(t) {
+		for _, s := range append(trickyNames, "") {
+			got, err := echoWord(shell, s)
+			if err != nil {
+				t.Errorf("%s: %q: %v", shell, s, err)
+			} else if got != s {
+				t.Errorf("%s: got %q, want %q", shell, got, s)
+			}
+		}
+	}
+}
+
+// TestQuoteProperty checks the round trip for random strings.
+func TestQuoteProperty(t *testing.T) {
+	shells := shellsFor(t)
+	f := func(s string) bool {
+		s = strings.ReplaceAll(s, "\x00", "")
+		for _, shell := range shells {
+			if got, err := echoWord(shell, s); err != nil || got != s {
+				t.Logf("%s: got %q, want %q (%v)", shell, got, s, err)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 50}); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestDirCommandChangesDirectory runs dirCommand in real shells and checks
+// that each lands in the directory it was given.
+func TestDirCommandChangesDirectory(t *testing.T) {
+	shells := shellsFor(t)
+	root := t.TempDir()
+	for _, name := range trickyNames {
+		path := filepath.Join(root, name)
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for _, shell := range shells {
+			p := config.Profile{Cmd: "pwd", Shell: &config.Shell{Path: shell}}
+			out, err := exec.Command(shell, "-c", dirCommand(config.DirConfig{Path: path}, p)).Output()
+			if err != nil {
+				t.Errorf("%s: %q: %v", shell, name, err)
+				continue
+			}
+			if got := strings.TrimSuffix(string(out), "\n"); got != path {
+				t.Errorf("%s: landed in %q, want %q", shell, got, path)
+			}
+		}
+	}
+}
+
+// FuzzDirCommand checks that any directory name round-trips to the right
+// cd target.
+func FuzzDirCommand(f *testing.F) {
+	for _, name := range trickyNames {
+		f.Add(name)
+	}
+	shells := shellsFor(f)
+	root := f.TempDir()
+	f.Fuzz(func(t *testing.T, name string) {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\x00") {
+			t.Skip()
+		}
+		path := filepath.Join(root, name)
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Skip(err)
+		}
+		defer os.Remove(path)
+		for _, shell := range shells {
+			p := config.Profile{Cmd: "pwd", Shell: &config.Shell{Path: shell}}
+			out, err := exec.Command(shell, "-c", dirCommand(config.DirConfig{Path: path}, p)).Output()
+			if err != nil {
+				t.Fatalf("%s: %q: %v", shell, name, err)
+			}
+			if got := strings.TrimSuffix(string(out), "\n"); got != path {
+				t.Fatalf("%s: landed in %q, want %q", shell, got, path)
+			}
+		}
+	})
+}
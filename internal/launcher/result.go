@@ -0,0 +1,166 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// Status is the outcome of a single (directory, profile) launch.
+type Status int
+
+const (
+	StatusLaunched Status = iota // the terminal process was started
+	StatusSkipped                // nothing was started; see Reason
+	StatusFailed                 // starting failed; see Err
+	StatusPlanned                // dry run: would have been started
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusLaunched:
+		return "launched"
+	case StatusSkipped:
+		return "skipped"
+	case StatusFailed:
+		return "failed"
+	case StatusPlanned:
+		return "planned"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Result reports what happened to one (directory, profile) pair. Dir is the
+// zero value for failures that prevented the whole launch, such as no
+// terminal being available.
+type Result struct {
+	Dir       config.DirConfig
+	ProfileID string
+	Profile   config.Profile // zero value when the profile ID is dangling
+	Status    Status
+	Reason    string // why the item was skipped
+	Err       error  // why the item failed
+}
+
+// Target names the item as "dir → profile", or just the directory when no
+// profile is involved.
+func (r Result) Target() string {
+	switch {
+	case r.Dir.Name == "":
+		return ""
+	case r.Profile.Label != "":
+		return r.Dir.Name + " → " + r.Profile.Label
+	case r.ProfileID != "":
+		return r.Dir.Name + " → " + r.ProfileID
+	}
+	return r.Dir.Name
+}
+
+func (r Result) String() string {
+	var detail string
+	switch r.Status {
+	case StatusSkipped:
+		detail = r.Reason
+	case StatusFailed:
+		if r.Err != nil {
+			detail = r.Err.Error()
+		}
+	}
+	target := r.Target()
+	switch {
+	case target == "":
+		return fmt.Sprintf("%s: %s", r.Status, detail)
+	case detail == "":
+		return fmt.Sprintf("%s %s", r.Status, target)
+	}
+	return fmt.Sprintf("%s %s: %s", r.Status, target, detail)
+}
+
+// Failed reports whether any result failed.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Summarize condenses results into one status line such as
+// "7 launched, 1 failed: kitty not found for api-server".
+func Summarize(results []Result) string {
+	var counts [4]int
+	var failures []Result
+	for _, r := range results {
+		counts[r.Status]++
+		if r.Status == StatusFailed {
+			failures = append(failures, r)
+		}
+	}
+
+	var parts []string
+	for _, s := range []Status{StatusLaunched, StatusPlanned, StatusSkipped, StatusFailed} {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing to launch"
+	}
+	summary := strings.Join(parts, ", ")
+
+	if len(failures) > 0 {
+		f := failures[0]
+		if f.Err != nil {
+			summary += ": " + f.Err.Error()
+		}
+		if f.Dir.Name != "" {
+			summary += " for " + f.Dir.Name
+		}
+		if len(failures) > 1 {
+			summary += fmt.Sprintf(" (and %d more)", len(failures)-1)
+		}
+	}
+	return summary
+}
+
+// skipped converts the plan's warnings into skipped results, or failed
+// ones for those with an Err.
+func (p Plan) skipped() []Result {
+	results := make([]Result, 0, len(p.Warnings))
+	for _, w := range p.Warnings {
+		if w.Err != nil {
+			results = append(results, Result{Dir: w.Dir, ProfileID: w.ProfileID, Status: StatusFailed, Err: w.Err})
+			continue
+		}
+		results = append(results, Result{Dir: w.Dir, ProfileID: w.ProfileID, Status: StatusSkipped, Reason: w.Message})
+	}
+	return results
+}
+
+// skipError is returned by a start function to report its command as
+// skipped, with the error text as the reason, rather than failed.
+type skipError string
+
+func (e skipError) Error() string { return string(e) }
+
+// startError shortens the common "binary is not installed" error to
+// "kitty not found" while keeping the original error for errors.Is.
+type startError struct {
+	msg string
+	err error
+}
+
+func (e *startError) Error() string { return e.msg }
+func (e *startError) Unwrap() error { return e.err }
+
+func describeStartError(err error) error {
+	var execErr *exec.Error
+	if errors.As(err, &execErr) && errors.Is(execErr.Err, exec.ErrNotFound) {
+		return &startError{msg: execErr.Name + " not found", err: err}
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package launcher
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestSummarize(t *testing.T) {
+	api := config.DirConfig{Name: "api-server"}
+	web := config.DirConfig{Name: "web"}
+	tests := []struct {
+		name    string
+		results []Result
+		want    string
+	}{
+		{"empty", nil, "nothing to launch"},
+		{"all launched", []Result{{Dir: web, Status: StatusLaunched}, {Dir: api, Status: StatusLaunched}}, "2 launched"},
+		{
+			"mixed",
+			[]Result{
+				{Dir: web, Status: StatusLaunched},
+				{Dir: web, Status: StatusSkipped, Reason: "enabled but has no profiles"},
+				{Dir: api, Status: StatusFailed, Err: errors.New("kitty not found")},
+			},
+			"1 launched, 1 skipped, 1 failed: kitty not found for api-server",
+		},
+		{
+			"several failures",
+			[]Result{
+				{Dir: api, Status: StatusFailed, Err: errors.New("kitty not found")},
+				{Dir: web, Status: StatusFailed, Err: errors.New("kitty not found")},
+			},
+			"2 failed: kitty not found for api-server (and 1 more)",
+		},
+		{"whole launch failed", []Result{{Status: StatusFailed, Err: errors.New("no supported terminal emulator found")}}, "1 failed: no supported terminal emulator found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.results); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultString(t *testing.T) {
+	dir := config.DirConfig{Name: "web"}
+	tests := []struct {
+		r    Result
+		want string
+	}{
+		{Result{Dir: dir, Profile: config.Profile{Label: "Claude"}, Status: StatusLaunched}, "launched web → Claude"},
+		{Result{Dir: dir, ProfileID: "gone", Status: StatusSkipped, Reason: "profile gone no longer exists"}, "skipped web → gone: profile gone no longer exists"},
+		{Result{Status: StatusFailed, Err: errors.New("boom")}, "failed: boom"},
+	}
+	for _, tt := range tests {
+		if got := tt.r.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// secretTimeout bounds each cmd: secret, so a password manager waiting for
+// input it will never get cannot hang the launch.
+var secretTimeout = 10 * time.Second
+
+// staleEnvFile is the age after which an env file nobody sourced, because
+// its terminal never started a shell, is removed by the next launch. Kitty
+// socket directories are removed at the same age.
+const staleEnvFile = time.Hour
+
+// secrets resolves the secret references of one launch and writes their
+// values to env files. Each reference is read at most once per directory,
+// failures included, however many profiles use it.
+type secrets struct {
+	ctx    context.Context
+	read   map[[2]string]secretValue // by directory path and reference
+	pruned bool
+}
+
+type secretValue struct {
+	value string
+	err   error
+}
+
+func newSecrets(ctx context.Context) *secrets {
+	return &secrets{ctx: ctx, read: make(map[[2]string]secretValue)}
+}
+
+// splitSecrets moves the secret references out of env, the environment of
+// p in dir, returning the rest and the references. Only values from the
+// configuration, p's Env or dir's own, are references: those from the
+// directory's .env file, dotenv, stay as they are, so a cloned repository
+// cannot run commands through them. env is not modified.
+func splitSecrets(dir config.DirConfig, dotenv map[string]string, p config.Profile, env map[string]string) (plain, refs map[string]string) {
+	plain = env
+	for _, k := range config.EnvNames(env) {
+		ref, ok := dir.Env[k]
+		if !ok {
+			if _, ok := dotenv[k]; ok {
+				continue
+			}
+			ref = p.Env[k]
+		}
+		if !config.IsSecret(ref) {
+			continue
+		}
+		if refs == nil {
+			refs = make(map[string]string)
+			plain = make(map[string]string, len(env))
+			for k, v := range env {
+				plain[k] = v
+			}
+		}
+		refs[k] = ref
+		delete(plain, k)
+	}
+	return plain, refs
+}
+
+// splitDotenv moves the variables env takes from dotenv, the directory's
+// .env file, out of env, returning the rest and those values. They may be
+// as secret as any reference, so a launch writes them to the env file too
+// rather than into an argv. env is not modified.
+func splitDotenv(dir config.DirConfig, dotenv, env map[string]string) (plain, values map[string]string) {
+	plain = env
+	for _, k := range config.EnvNames(env) {
+		if _, ok := dir.Env[k]; ok {
+			continue
+		}
+		if _, ok := dotenv[k]; !ok {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+			plain = make(map[string]string, len(env))
+			for k, v := range env {
+				plain[k] = v
+			}
+		}
+		values[k] = env[k]
+		delete(plain, k)
+	}
+	return plain, values
+}
+
+// hideDotenv returns refs with the names of values, read from a .env file,
+// added as config.DotenvSecret. refs is not modified.
+func hideDotenv(refs, values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return refs
+	}
+	hidden := make(map[string]string, len(refs)+len(values))
+	for k, v := range refs {
+		hidden[k] = v
+	}
+	for k := range values {
+		hidden[k] = config.DotenvSecret
+	}
+	return hidden
+}
+
+// envFile resolves refs, the secret variables of a profile in dir, and
+// writes them with values, taken as they are, to a new env file only the
+// user can read, returning its path. The error names every variable that
+// could not be resolved.
+func (s *secrets) envFile(dir config.DirConfig, refs, values map[string]string) (string, error) {
+	var sb strings.Builder
+	var failed []string
+	for _, k := range config.EnvNames(values) {
+		sb.WriteString("export " + k + "=" + quoteEnvFile(values[k]) + "\n")
+	}
+	for _, k := range config.EnvNames(refs) {
+		key := [2]string{dir.Path, refs[k]}
+		v, ok := s.read[key]
+		if !ok {
+			v.value, v.err = readSecret(s.ctx, dir, refs[k])
+			s.read[key] = v
+		}
+		if v.err != nil {
+			failed = append(failed, fmt.Sprintf("secret %s: %v", k, v.err))
+			continue
+		}
+		sb.WriteString("export " + k + "=" + quoteEnvFile(v.value) + "\n")
+	}
+	if len(failed) > 0 {
+		return "", errors.New(strings.Join(failed, "; "))
+	}
+
+	files, err := envFileDir()
+	if err != nil {
+		return "", err
+	}
+	if !s.pruned {
+		s.pruned = true
+		pruneStale(files, "env-*")
+	}
+	f, err := os.CreateTemp(files, "env-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.WriteString(sb.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// envFileDir is where env files are written: gopener's directory in the
+// user's runtime directory, which is in memory on most systems, or under
+// config.StateDir without one. Only the user can enter it.
+func envFileDir() (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir != "" {
+		dir = filepath.Join(dir, "gopener")
+	} else {
+		state, err := config.StateDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(state, "secrets")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, os.Chmod(dir, 0700)
+}
+
+// pruneStale removes the files and directories in dir matching pattern
+// that are older than staleEnvFile.
+func pruneStale(dir, pattern string) {
+	paths, _ := filepath.Glob(filepath.Join(dir, pattern))
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > staleEnvFile {
+			os.RemoveAll(path)
+		}
+	}
+}
+
+// removeEnvFiles removes the env files of the commands that did not
+// launch, since no shell will source and remove them.
+func removeEnvFiles(commands []Command, results []Result) {
+	launched := make(map[[2]string]bool)
+	for _, r := range results {
+		if r.Status == StatusLaunched {
+			launched[[2]string{r.Dir.Path, r.ProfileID}] = true
+		}
+	}
+	for _, c := range commands {
+		if c.Profile.EnvFile != "" && !launched[[2]string{c.Dir.Path, c.Profile.ID}] {
+			os.Remove(c.Profile.EnvFile)
+		}
+	}
+}
+
+// quoteEnvFile quotes s so POSIX shells and fish read it alike. Inside
+// single quotes fish still takes \\ and \' as escapes, so quotes and
+// backslashes are escaped outside them.
+func quoteEnvFile(s string) string {
+	return "'" + strings.NewReplacer(`'`, `'\''`, `\`, `'\\'`).Replace(s) + "'"
+}
+
+// readSecret reads the secret ref refers to. Commands run by sh in dir,
+// and relative paths are relative to it; one trailing newline is dropped
+// from either. Errors never include the secret, and an empty secret is
+// an error.
+func readSecret(ctx context.Context, dir config.DirConfig, ref string) (string, error) {
+	var value string
+	if path, ok := strings.CutPrefix(ref, config.SecretFile); ok {
+		path = strings.TrimSpace(path)
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			path = filepath.Join(home, rest)
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(dir.Path, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		value = trimNewline(string(data))
+		if value == "" {
+			return "", fmt.Errorf("%s is empty", path)
+		}
+		return value, nil
+	}
+
+	line := strings.TrimSpace(strings.TrimPrefix(ref, config.SecretCmd))
+	ctx, cancel := context.WithTimeout(ctx, secretTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", line)
+	cmd.Dir = dir.Path
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// A child still holding the output open must not outlive the timeout.
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", fmt.Errorf("%s: timed out after %s", line, secretTimeout)
+	case ctx.Err() != nil:
+		return "", fmt.Errorf("%s: %v", line, ctx.Err())
+	case err != nil:
+		if msg := lastLine(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %v: %s", line, err, msg)
+		}
+		return "", fmt.Errorf("%s: %v", line, err)
+	}
+	value = trimNewline(string(out))
+	if value == "" {
+		return "", fmt.Errorf("%s: printed nothing", line)
+	}
+	return value, nil
+}
+
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// lastLine is the last non-blank line of s, which for most tools is the
+// one saying what went wrong.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
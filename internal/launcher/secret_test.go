@@ -0,0 +1,167 @@
+package launcher
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestReadSecret(t *testing.T) {
+	dir := config.DirConfig{Path: t.TempDir()}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, "key"), []byte("from-home\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir.Path, "token"), []byte("from-dir"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir.Path, "empty"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref, want string
+		wantErr   string
+	}{
+		{"cmd:echo s3cret", "s3cret", ""},
+		{"cmd: printf 'two\\nlines\\n'", "two\nlines", ""},
+		{"cmd:cat token", "from-dir", ""}, // run in the directory
+		{"cmd:echo nope >&2; echo 'not in the store' >&2; exit 1", "", "exit status 1: not in the store"},
+		{"cmd:true", "", "printed nothing"},
+		{"file:~/key", "from-home", ""},
+		{"file:token", "from-dir", ""},
+		{"file:" + filepath.Join(home, "key"), "from-home", ""},
+		{"file:missing", "", "no such file"},
+		{"file:empty", "", "is empty"},
+	}
+	for _, tt := range tests {
+		got, err := readSecret(context.Background(), dir, tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestReadSecretTimeout(t *testing.T) {
+	defer func(d time.Duration) { secretTimeout = d }(secretTimeout)
+	secretTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := readSecret(context.Background(), config.DirConfig{Path: t.TempDir()}, "cmd:sleep 5")
+	if err == nil || !strings.Contains(err.Error(), "sleep 5: timed out after 100ms") {
+		t.Errorf("err = %v", err)
+	}
+	if d := time.Since(start); d > 3*time.Second {
+		t.Errorf("took %s", d)
+	}
+}
+
+func TestNewPlanSecrets(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	count := filepath.Join(t.TempDir(), "count")
+	cfg := planFixture()
+	cfg.Profiles[0].Env = map[string]string{"TOKEN": "cmd:echo x >> " + count + "; echo s3cret", "PLAIN": "yes"}
+	cfg.Profiles[1].Env = map[string]string{"TOKEN": "cmd:echo x >> " + count + "; echo s3cret", "BROKEN": "cmd:exit 3"}
+	cfg.Profiles = append(cfg.Profiles, config.Profile{ID: "p3", Label: "Editor", Cmd: "code .", Kind: config.KindGUI,
+		Env: map[string]string{"TOKEN": "cmd:echo x >> " + count + "; echo s3cret"}})
+	web := &cfg.Directories[1]
+	web.Path = t.TempDir() // where the commands run
+	web.ProfileIDs = append(web.ProfileIDs, "p3")
+	// A .env file is taken literally, whatever it holds.
+	web.Activate = config.ActivateDotenv
+	if err := os.WriteFile(filepath.Join(web.Path, ".env"), []byte("EVIL=cmd:touch pwned\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Plans keep the reference, hide the .env value and source a
+	// placeholder env file.
+	plan := NewPlan(cfg, cfg.Directories)
+	if p := plan.Commands[0].Profile; p.Secrets["TOKEN"] == "" || p.Env["TOKEN"] != "" || p.Env["EVIL"] != "" ||
+		p.Secrets["EVIL"] != config.DotenvSecret || p.EnvFile != envFilePlaceholder {
+		t.Errorf("planned profile: env %v, secrets %v, file %q", p.Env, p.Secrets, p.EnvFile)
+	}
+	if argv := strings.Join(plan.Commands[0].Argv, " "); strings.Contains(argv, "pwned") || !strings.Contains(argv, ". '"+envFilePlaceholder+"'") {
+		t.Errorf("planned argv %q", plan.Commands[0].Argv)
+	}
+	if _, err := os.Stat(count); err == nil {
+		t.Fatal("NewPlan ran a secret command")
+	}
+
+	plan = newPlan(cfg, cfg.Directories, newSecrets(context.Background()))
+	if len(plan.Commands) != 2 {
+		t.Fatalf("expected Claude and Editor, got %+v", plan.Commands)
+	}
+	p := plan.Commands[0].Profile
+	if p.Env["PLAIN"] != "yes" || p.Env["EVIL"] != "" || p.Secrets["EVIL"] != config.DotenvSecret {
+		t.Errorf("env = %v, secrets = %v", p.Env, p.Secrets)
+	}
+	if _, err := os.Stat(filepath.Join(web.Path, "pwned")); err == nil {
+		t.Error("a .env value ran as a secret command")
+	}
+	// The values are only in the env file, which the shell sources and
+	// removes, .env ones included.
+	for _, c := range plan.Commands {
+		if argv := strings.Join(c.Argv, " "); strings.Contains(argv, "s3cret") || strings.Contains(argv, "pwned") || !strings.Contains(argv, "rm -f "+c.Profile.EnvFile) {
+			t.Errorf("%s: argv %q", c.Profile.Label, c.Argv)
+		}
+	}
+	if fi, err := os.Stat(p.EnvFile); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("env file: %v, %v", fi, err)
+	}
+	if data, _ := os.ReadFile(p.EnvFile); string(data) != "export EVIL='cmd:touch pwned'\nexport TOKEN='s3cret'\n" {
+		t.Errorf("env file holds %q", data)
+	}
+	// The same reference is read once for the directory.
+	if data, err := os.ReadFile(count); err != nil || string(data) != "x\n" {
+		t.Errorf("secret command ran %q times, %v", data, err)
+	}
+
+	// A secret that cannot be read fails its profile.
+	var found bool
+	for _, r := range plan.skipped() {
+		found = found || (r.ProfileID == "p2" && r.Status == StatusFailed && r.Err.Error() == "profile Shell: secret BROKEN: exit 3: exit status 3")
+	}
+	if !found {
+		t.Errorf("expected a failure for the broken secret, got %+v", plan.Warnings)
+	}
+	// The configuration, which Save writes, never sees the values.
+	if got := cfg.Profiles[0].Env["TOKEN"]; !strings.HasPrefix(got, "cmd:") {
+		t.Errorf("config env changed to %q", got)
+	}
+
+	// Files of commands that did not launch are removed.
+	removeEnvFiles(plan.Commands, []Result{{Dir: plan.Commands[1].Dir, ProfileID: "p3", Status: StatusLaunched}})
+	if _, err := os.Stat(p.EnvFile); err == nil {
+		t.Error("env file of an unlaunched command is left")
+	}
+	if _, err := os.Stat(plan.Commands[1].Profile.EnvFile); err != nil {
+		t.Errorf("env file of a launched command: %v", err)
+	}
+}
+
+func TestQuoteEnvFile(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	for _, v := range []string{"plain", "it's", `back\slash`, `\\'`, "two\nlines", "$HOME `x`"} {
+		out, err := exec.Command(sh, "-c", "export V="+quoteEnvFile(v)+"; printf %s \"$V\"").Output()
+		if err != nil || string(out) != v {
+			t.Errorf("%q: got %q, %v", v, out, err)
+		}
+	}
+}
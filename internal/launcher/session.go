@@ -0,0 +1,260 @@
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// Session is a process gopener started for a profile in a directory: a
+// terminal window or a GUI application. Sessions are recorded in a state
+// file so any later gopener can show whether they still run and stop them.
+//
+// A terminal that hands the window to an already running instance, as
+// gnome-terminal, "wezterm start" and the macOS terminals opened through
+// osascript do, exits as soon as it has, so its session shows as exited
+// while the window stays open, and stopping it cannot close the window.
+type Session struct {
+	Dir       string    `json:"dir"` // DirConfig.Path
+	ProfileID string    `json:"profile"`
+	PID       int       `json:"pid"`
+	PGID      int       `json:"pgid"`
+	Started   time.Time `json:"started"`
+
+	// ProcessStart is when the process started as the OS reports it, see
+	// processStart, so a later process reusing the PID is not taken for it.
+	ProcessStart uint64 `json:"process_start,omitempty"`
+
+	// Running is set by Sessions from the process table.
+	Running bool `json:"-"`
+}
+
+// sessionsPath is the state file under config.StateDir.
+func sessionsPath() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions.json"), nil
+}
+
+// Sessions returns the recorded sessions, oldest first, with Running set.
+// There are none before the first launch.
+func Sessions() ([]Session, error) {
+	sessions, err := readSessions()
+	for i := range sessions {
+		sessions[i].Running = sessions[i].alive()
+	}
+	return sessions, err
+}
+
+func readSessions() ([]Session, error) {
+	path, err := sessionsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sessions []Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// writeSessions replaces the state file through a temporary file of its
+// own and a rename, so readers never see it half written.
+func writeSessions(path string, sessions []Session) error {
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// updateSessions rewrites the state file with f applied to its sessions.
+// Every gopener, the CLI and TUI alike, holds a lock on the file next to it
+// from the read to the rename, so no change is lost.
+func updateSessions(f func([]Session) []Session) error {
+	path, err := sessionsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	sessions, err := readSessions()
+	if err != nil {
+		return err
+	}
+	return writeSessions(path, f(sessions))
+}
+
+// recordSession adds s, dropping the exited sessions of the same profile in
+// the same directory, which it replaces.
+func recordSession(s Session) error {
+	return updateSessions(func(sessions []Session) []Session {
+		kept := sessions[:0]
+		for _, old := range sessions {
+			if old.Dir != s.Dir || old.ProfileID != s.ProfileID || old.alive() {
+				kept = append(kept, old)
+			}
+		}
+		return append(kept, s)
+	})
+}
+
+// forgetSessions removes the sessions for which drop returns true.
+func forgetSessions(drop func(Session) bool) error {
+	return updateSessions(func(sessions []Session) []Session {
+		kept := sessions[:0]
+		for _, s := range sessions {
+			if !drop(s) {
+				kept = append(kept, s)
+			}
+		}
+		return kept
+	})
+}
+
+// alive reports whether any process of s's group is left. While the
+// group's leader runs it must be the process gopener started: one that
+// started at another time reuses the PID. Once the leader is gone, the
+// kernel does not reuse its PID for as long as the group has members, so
+// these are the session's, unless gopener may not signal them, in which
+// case the group is someone else's. A session without a start time cannot
+// be told from a stranger, so it never counts.
+func (s Session) alive() bool {
+	if s.PGID <= 0 || s.ProcessStart == 0 {
+		return false
+	}
+	if start, err := processStart(s.PID); err == nil && start != s.ProcessStart {
+		return false
+	}
+	return syscall.Kill(-s.PGID, 0) == nil
+}
+
+func (s Session) same(o Session) bool {
+	return s.PID == o.PID && s.Started.Equal(o.Started)
+}
+
+// spawn starts cmd for c in a process group of its own, so the group can be
+// stopped as a whole and ignores signals sent to gopener's terminal, and
+// records it as c's session. The process is reaped once it exits. A state
+// file that cannot be written leaves the session untracked rather than
+// failing the launch.
+//
+// Only processes gopener starts itself are sessions: tabs and multiplexer
+// windows belong to a process that was already running, and a grouped
+// window is recorded for the profile that opened it.
+func spawn(c Command, cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Read before the process can exit and be reaped; one that is already
+	// gone is never alive anyway.
+	start, _ := processStart(cmd.Process.Pid)
+	go cmd.Wait()
+	_ = recordSession(Session{
+		Dir:          c.Dir.Path,
+		ProfileID:    c.Profile.ID,
+		PID:          cmd.Process.Pid,
+		PGID:         cmd.Process.Pid,
+		Started:      time.Now(),
+		ProcessStart: start,
+	})
+	return nil
+}
+
+// startArgv spawns argv for c.
+func startArgv(c Command, argv []string) error {
+	return spawn(c, exec.Command(argv[0], argv[1:]...))
+}
+
+// startReaped starts argv without recording a session and reaps it once it
+// exits. It is for commands asking a running terminal for a tab, which
+// belongs to that terminal.
+func startReaped(argv []string) error {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// Stop ends the given sessions and forgets them: their process groups get
+// SIGTERM, and those still running after timeout get SIGKILL. Sessions
+// that already exited are only forgotten.
+func Stop(sessions []Session, timeout time.Duration) error {
+	var errs []error
+	signal := func(sig syscall.Signal) {
+		for _, s := range sessions {
+			if !s.alive() {
+				continue
+			}
+			if err := syscall.Kill(-s.PGID, sig); err != nil && !errors.Is(err, syscall.ESRCH) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	anyAlive := func() bool {
+		for _, s := range sessions {
+			if s.alive() {
+				return true
+			}
+		}
+		return false
+	}
+
+	signal(syscall.SIGTERM)
+	for deadline := time.Now().Add(timeout); anyAlive() && time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+	}
+	signal(syscall.SIGKILL)
+
+	err := forgetSessions(func(s Session) bool {
+		for _, stopped := range sessions {
+			if s.same(stopped) {
+				return true
+			}
+		}
+		return false
+	})
+	return errors.Join(append(errs, err)...)
+}
@@ -0,0 +1,14 @@
+package launcher
+
+import "golang.org/x/sys/unix"
+
+// processStart returns when pid started, in microseconds since the epoch,
+// from the kernel's process table.
+func processStart(pid int) (uint64, error) {
+	kp, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil {
+		return 0, err
+	}
+	t := kp.Proc.P_starttime
+	return uint64(t.Sec)*1e6 + uint64(t.Usec), nil
+}
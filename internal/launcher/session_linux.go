@@ -0,0 +1,27 @@
+package launcher
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStart returns when pid started, in clock ticks since boot: field
+// 22 of /proc/<pid>/stat. Fields are counted from the last ')', since the
+// process name before it may contain spaces and parentheses.
+func processStart(pid int) (uint64, error) {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0, err
+	}
+	s := string(b)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return 0, os.ErrInvalid
+	}
+	fields := strings.Fields(s[i+1:])
+	if len(fields) < 20 {
+		return 0, os.ErrInvalid
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
@@ -0,0 +1,137 @@
+package launcher
+
+import (
+	"fmt"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func sessionCommand(path, profileID string) Command {
+	return Command{Dir: config.DirConfig{Path: path}, Profile: config.Profile{ID: profileID}}
+}
+
+func waitExited(t *testing.T, s Session) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); s.alive(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("session %d still running", s.PID)
+		}
+	}
+}
+
+func TestSpawnRecordsSessions(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	if got, err := Sessions(); got != nil || err != nil {
+		t.Fatalf("before any launch: %v, %v", got, err)
+	}
+
+	if err := startArgv(sessionCommand("/src/web", "p1"), []string{"sleep", "30"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := startArgv(sessionCommand("/src/web", "p2"), []string{"true"}); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := Sessions()
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("got %+v, %v", sessions, err)
+	}
+	running := sessions[0]
+	defer Stop([]Session{running}, 0)
+	if running.ProfileID != "p1" || running.Dir != "/src/web" || !running.Running || running.PGID != running.PID || running.ProcessStart == 0 {
+		t.Errorf("first session: %+v", running)
+	}
+	// A process that started at another time reuses the PID.
+	stranger := running
+	stranger.ProcessStart++
+	if stranger.alive() {
+		t.Error("a session with another start time counts as running")
+	}
+	waitExited(t, sessions[1])
+	if sessions, _ = Sessions(); sessions[1].Running {
+		t.Errorf("second session should have exited: %+v", sessions[1])
+	}
+
+	// Launching p2 again replaces its exited session; p1's running one
+	// stays next to a new one.
+	if err := startArgv(sessionCommand("/src/web", "p2"), []string{"true"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := startArgv(sessionCommand("/src/web", "p1"), []string{"sleep", "30"}); err != nil {
+		t.Fatal(err)
+	}
+	sessions, _ = Sessions()
+	defer Stop(sessions, 0)
+	if len(sessions) != 3 || sessions[0].PID != running.PID || sessions[1].ProfileID != "p2" || sessions[2].ProfileID != "p1" {
+		t.Errorf("got %+v", sessions)
+	}
+}
+
+func TestRecordSessionConcurrently(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := recordSession(Session{Dir: "/src/web", ProfileID: fmt.Sprint(i)}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if sessions, err := Sessions(); len(sessions) != 20 || err != nil {
+		t.Errorf("got %d sessions, %v", len(sessions), err)
+	}
+}
+
+func TestStop(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	// The shell's child is in the same group, so it is stopped too; the
+	// stubborn session ignores SIGTERM and needs SIGKILL.
+	if err := startArgv(sessionCommand("/src/web", "polite"), []string{sh, "-c", "sleep 30; true"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := startArgv(sessionCommand("/src/api", "stubborn"), []string{sh, "-c", "trap '' TERM; sleep 30; true"}); err != nil {
+		t.Fatal(err)
+	}
+	sessions, _ := Sessions()
+	if len(sessions) != 2 {
+		t.Fatalf("got %+v", sessions)
+	}
+	time.Sleep(100 * time.Millisecond) // let the trap be set
+
+	// Orphans are reaped by init, which may take a moment, so only a
+	// stop taking the whole timeout means SIGTERM was not enough.
+	start := time.Now()
+	if err := Stop(sessions[:1], 10*time.Second); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	waitExited(t, sessions[0])
+	if d := time.Since(start); d > 9*time.Second {
+		t.Errorf("SIGTERM should have been enough, took %s", d)
+	}
+	left, _ := Sessions()
+	if len(left) != 1 || left[0].ProfileID != "stubborn" || !left[0].Running {
+		t.Fatalf("after stopping one: %+v", left)
+	}
+
+	start = time.Now()
+	if err := Stop(left, 200*time.Millisecond); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	waitExited(t, left[0])
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("SIGKILL should follow the timeout, took %s", d)
+	}
+	if left, _ := Sessions(); len(left) != 0 {
+		t.Errorf("stopped sessions should be forgotten: %+v", left)
+	}
+}
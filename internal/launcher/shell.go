@@ -0,0 +1,120 @@
+package launcher
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// posixLogin reads the files a login shell would for shells without a
+// login flag.
+const posixLogin = `[ -r /etc/profile ] && . /etc/profile; [ -r "$HOME/.profile" ] && . "$HOME/.profile"; `
+
+// shellArgv is the argv running cmd in p's shell, followed by p's exit
+// policy, and started through p.Wrap. NewPlan resolves p.Shell from the
+// profile and global settings; a profile without one runs in
+// config.DefaultShell.
+//
+// bash, zsh, ksh and fish all take -l, -i and -c. POSIX sh only guarantees
+// -i and -c, so a login sh sources the profile files itself.
+func shellArgv(p config.Profile, cmd string) []string {
+	return policyArgv(p, cmd, false)
+}
+
+// heldShellArgv is shellArgv for a window the terminal keeps open itself
+// once the command exits, so the hold policy only reports the exit status.
+func heldShellArgv(p config.Profile, cmd string) []string {
+	return policyArgv(p, cmd, true)
+}
+
+func policyArgv(p config.Profile, cmd string, held bool) []string {
+	sh := profileShell(p)
+	flags, prelude := shellFlags(sh)
+	argv := append(append(append([]string{}, p.Wrap...), sh.Path), flags...)
+	return append(argv, "-c", prelude+envCommand(p)+exitCommand(p, cmd, held))
+}
+
+// wrapCommand is a command line for shell running cmd in shell through
+// p.Wrap, or cmd itself when p has no wrapper. It is for commands typed or
+// templated into a shell gopener does not start itself.
+func wrapCommand(p config.Profile, shell, cmd string) string {
+	if len(p.Wrap) == 0 {
+		return cmd
+	}
+	return joinFor(shell, append(append([]string{}, p.Wrap...), shell, "-c", cmd))
+}
+
+// envCommand exports p.Env in the syntax of p's shell, one variable per
+// line in name order. It follows the login prelude so profile files cannot
+// undo it. NewPlan resolves p.Env from the profile and directory settings;
+// a launch adds the values of p.Secrets by sourcing p.EnvFile.
+func envCommand(p config.Profile) string {
+	sh := profileShell(p).Path
+	quote := quoterFor(sh)
+	var sb strings.Builder
+	for _, k := range config.EnvNames(p.Env) {
+		if filepath.Base(sh) == "fish" {
+			sb.WriteString("set -gx " + k + " " + quote(p.Env[k]) + "\n")
+		} else {
+			sb.WriteString("export " + k + "=" + quote(p.Env[k]) + "\n")
+		}
+	}
+	if p.EnvFile != "" {
+		// Sourced rather than exported, so no secret is ever part of an
+		// argv, a layout or a typed line; the file is gone once read.
+		if filepath.Base(sh) == "fish" {
+			sb.WriteString("source " + quote(p.EnvFile) + "\n")
+		} else {
+			sb.WriteString(". " + quote(p.EnvFile) + "\n")
+		}
+		sb.WriteString("rm -f " + quote(p.EnvFile) + "\n")
+	}
+	return sb.String()
+}
+
+// shellFlags returns the options starting sh as configured, and a prelude
+// for the command when the shell has no login option.
+func shellFlags(sh config.Shell) (flags []string, prelude string) {
+	if sh.Login {
+		switch filepath.Base(sh.Path) {
+		case "bash", "zsh", "ksh", "mksh", "fish":
+			flags = append(flags, "-l")
+		default:
+			prelude = posixLogin
+		}
+	}
+	if sh.Interactive {
+		flags = append(flags, "-i")
+	}
+	return flags, prelude
+}
+
+// exitCommand appends p's exit policy to cmd, in the syntax of p's shell.
+// Commands are separated by newlines so a comment at the end of cmd cannot
+// swallow the policy. held means the terminal keeps the window open by
+// itself, so hold does not wait for a key.
+func exitCommand(p config.Profile, cmd string, held bool) string {
+	sh := profileShell(p)
+	fish := filepath.Base(sh.Path) == "fish"
+	switch p.ExitPolicy() {
+	case config.ExitHold:
+		status := `s=$?`
+		if fish {
+			status = `set -l s $status`
+		}
+		if held {
+			return cmd + "\n" + status + "\n" + `printf '\n[exited with status %d]\n' "$s"`
+		}
+		wait := `read -r s`
+		if fish {
+			wait = `read -P '' s`
+		}
+		return cmd + "\n" + status + "\n" + `printf '\n[exited with status %d] press enter to close' "$s"` + "\n" + wait
+	case config.ExitShell:
+		sh.Interactive = true
+		flags, _ := shellFlags(sh)
+		return cmd + "\nexec " + joinFor(sh.Path, append([]string{sh.Path}, flags...))
+	}
+	return cmd
+}
@@ -0,0 +1,170 @@
+package launcher
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestShellArgv(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	tests := []struct {
+		name  string
+		shell *config.Shell
+		want  []string
+	}{
+		{"default is $SHELL", nil, []string{"/usr/bin/zsh", "-c", "make"}},
+		{"bash login interactive", &config.Shell{Path: "bash", Login: true, Interactive: true},
+			[]string{"bash", "-l", "-i", "-c", "make"}},
+		{"zsh login", &config.Shell{Path: "/bin/zsh", Login: true}, []string{"/bin/zsh", "-l", "-c", "make"}},
+		{"fish interactive", &config.Shell{Path: "/usr/bin/fish", Interactive: true},
+			[]string{"/usr/bin/fish", "-i", "-c", "make"}},
+		{"sh login sources profile", &config.Shell{Path: "/bin/sh", Login: true},
+			[]string{"/bin/sh", "-c", posixLogin + "make"}},
+		{"dash interactive", &config.Shell{Path: "dash", Interactive: true}, []string{"dash", "-i", "-c", "make"}},
+		{"empty path uses default", &config.Shell{Login: true}, []string{"/usr/bin/zsh", "-c", "make"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := config.Profile{Cmd: "make", Shell: tt.shell}
+			if got := shellArgv(p, p.Cmd); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPlanResolvesShell(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	cfg := planFixture()
+	cfg.Shell = config.Shell{Path: "zsh", Login: true}
+	cfg.Profiles[1].Shell = &config.Shell{Path: "fish", Interactive: true}
+
+	plan := NewPlan(cfg, cfg.Directories)
+	shells := make(map[string]string)
+	for _, c := range plan.Commands {
+		shells[c.Profile.ID] = c.Profile.Shell.String()
+	}
+	if shells[cfg.Profiles[0].ID] != "zsh -l" || shells[cfg.Profiles[1].ID] != "fish -i" {
+		t.Errorf("resolved shells: got %v", shells)
+	}
+}
+
+func TestExitCommand(t *testing.T) {
+	hold := `\n[exited with status %d]`
+	tests := []struct {
+		name   string
+		shell  string
+		policy string
+		held   bool
+		want   string
+	}{
+		{"close", "bash", "", false, "make"},
+		{"explicit close", "bash", config.ExitClose, false, "make"},
+		{"hold", "bash", config.ExitHold, false,
+			"make\ns=$?\nprintf '" + hold + " press enter to close' \"$s\"\nread -r s"},
+		{"held", "bash", config.ExitHold, true,
+			"make\ns=$?\nprintf '" + hold + "\\n' \"$s\""},
+		{"fish hold", "/usr/bin/fish", config.ExitHold, false,
+			"make\nset -l s $status\nprintf '" + hold + " press enter to close' \"$s\"\nread -P '' s"},
+		{"shell", "zsh", config.ExitShell, false, "make\nexec zsh -i"},
+		{"login shell", "/bin/sh", config.ExitShell, false, "make\nexec /bin/sh -i"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := config.Profile{Cmd: "make", Shell: &config.Shell{Path: tt.shell}, OnExit: tt.policy}
+			if got := exitCommand(p, "make", tt.held); got != tt.want {
+				t.Errorf("\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExitPolicyRuns runs each policy in a real shell with a failing
+// command and checks what happens after it exits.
+func TestExitPolicyRuns(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	run := func(policy, stdin string) string {
+		t.Helper()
+		p := config.Profile{Shell: &config.Shell{Path: sh}, OnExit: policy}
+		argv := shellArgv(p, "echo out; sh -c 'exit 3' # a comment")
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Stdin = strings.NewReader(stdin)
+		out, _ := cmd.Output()
+		return string(out)
+	}
+
+	if got := run(config.ExitClose, ""); got != "out\n" {
+		t.Errorf("close: got %q", got)
+	}
+	if got := run(config.ExitHold, "\n"); got != "out\n\n[exited with status 3] press enter to close" {
+		t.Errorf("hold: got %q", got)
+	}
+	// The interactive shell reads the rest of stdin.
+	if got := run(config.ExitShell, "echo still here\n"); !strings.Contains(got, "still here") {
+		t.Errorf("shell: got %q", got)
+	}
+}
+
+func TestEnvCommand(t *testing.T) {
+	env := map[string]string{"NODE_ENV": "dev", "GREETING": "it's here"}
+	sh := config.Profile{Shell: &config.Shell{Path: "/bin/bash"}, Env: env}
+	if got, want := envCommand(sh), "export GREETING='it'\\''s here'\nexport NODE_ENV=dev\n"; got != want {
+		t.Errorf("bash:\n got %q\nwant %q", got, want)
+	}
+	fish := config.Profile{Shell: &config.Shell{Path: "fish"}, Env: env}
+	if got, want := envCommand(fish), "set -gx GREETING 'it\\'s here'\nset -gx NODE_ENV dev\n"; got != want {
+		t.Errorf("fish:\n got %q\nwant %q", got, want)
+	}
+	if got := envCommand(config.Profile{}); got != "" {
+		t.Errorf("no env: got %q", got)
+	}
+}
+
+// TestEnvReachesCommand checks that every installed shell passes the
+// variables on to the command.
+func TestEnvReachesCommand(t *testing.T) {
+	for _, shell := range shellsFor(t) {
+		for _, value := range append(trickyNames, "") {
+			p := config.Profile{
+				Shell: &config.Shell{Path: shell},
+				Env:   map[string]string{"GOPENER_TEST_VALUE": value},
+			}
+			argv := shellArgv(p, "printenv GOPENER_TEST_VALUE")
+			out, err := exec.Command(argv[0], argv[1:]...).Output()
+			if err != nil {
+				t.Errorf("%s: %q: %v", shell, value, err)
+			} else if got := strings.TrimSuffix(string(out), "\n"); got != value {
+				t.Errorf("%s: got %q, want %q", shell, got, value)
+			}
+		}
+	}
+}
+
+func TestNewPlanMergesEnv(t *testing.T) {
+	cfg := planFixture()
+	cfg.Profiles[0].Env = map[string]string{"NODE_ENV": "development", "TOOL": "claude"}
+	cfg.Directories[1].Env = map[string]string{"NODE_ENV": "production"}
+	cfg.Profiles[1].Env = map[string]string{"BAD NAME": "x"}
+
+	plan := NewPlan(cfg, cfg.Directories)
+	if len(plan.Commands) != 1 {
+		t.Fatalf("expected 1 command, got %+v", plan.Commands)
+	}
+	c := plan.Commands[0]
+	if want := map[string]string{"NODE_ENV": "production", "TOOL": "claude"}; !reflect.DeepEqual(c.Profile.Env, want) {
+		t.Errorf("env: got %v", c.Profile.Env)
+	}
+	if argv := JoinArgv(c.Argv); !strings.Contains(argv, "export NODE_ENV=production") {
+		t.Errorf("argv should export the env: %s", argv)
+	}
+	if len(plan.Warnings) != 3 || !strings.Contains(plan.Warnings[1].Message, "BAD NAME") {
+		t.Errorf("expected a warning for the invalid name, got %+v", plan.Warnings)
+	}
+}
@@ -0,0 +1,123 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// Profile commands are Go templates rendered per directory with cmdData:
+// {{.Name}}, {{.Path}}, {{.Branch}} and {{.SrcDir}}, {{env "VAR"}} for a
+// variable of gopener's environment, and everything text/template offers,
+// such as {{if .Branch}}…{{end}} or {{printf "%s-dev" .Name}}. What an
+// action prints is quoted for the profile's shell; "raw" as its last
+// command, as in {{raw .Path}} or {{.Path | raw}}, inserts it as it is, and
+// "quote" spells out the default. A string constant on its own, such as
+// {{"{{"}}, is printed as written, for tools with templates of their own:
+// docker ps --format '{{"{{"}}.Names}}'. Unknown fields and functions are
+// errors, so a misspelt placeholder never reaches the shell.
+
+// cmdData is what the placeholders of a profile command are filled in
+// from.
+type cmdData struct {
+	Name   string // directory name
+	Path   string // directory path
+	SrcDir string // Config.SrcDir
+
+	branch *string // cached by Branch
+}
+
+// Branch is the git branch checked out in the directory, "" when it is not
+// a repository or HEAD is detached. git only runs for commands using it.
+func (d *cmdData) Branch() string {
+	if d.branch == nil {
+		out, _ := exec.Command("git", "-C", d.Path, "symbolic-ref", "--short", "-q", "HEAD").Output()
+		b := strings.TrimSpace(string(out))
+		d.branch = &b
+	}
+	return *d.branch
+}
+
+// CheckCmd reports a syntax error, or an unknown field or function, in a
+// profile command, by rendering it for a sample directory.
+func CheckCmd(cmd string) error {
+	empty := ""
+	_, err := renderCmd(cmd, "sh", &cmdData{Name: "dir", Path: "/dir", branch: &empty})
+	return err
+}
+
+// profileCmd renders the placeholders in p's command for dir. Commands
+// without any are returned unchanged.
+func profileCmd(cfg *config.Config, dir config.DirConfig, p config.Profile) (string, error) {
+	return renderCmd(p.Cmd, profileShell(p).Path, &cmdData{Name: dir.Name, Path: dir.Path, SrcDir: cfg.SrcDir})
+}
+
+func renderCmd(cmd, shell string, data *cmdData) (string, error) {
+	if !strings.Contains(cmd, "{{") {
+		return cmd, nil
+	}
+	tmpl, err := template.New("command").Funcs(template.FuncMap{
+		"env":   os.Getenv,
+		"raw":   func(s string) string { return s },
+		"quote": quoterFor(shell),
+	}).Parse(cmd)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			quoteActions(t.Tree.Root)
+		}
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		if strings.Contains(err.Error(), "can't evaluate field") {
+			return "", fmt.Errorf(`%w (write {{"{{"}} for a literal {{)`, err)
+		}
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// quoteActions appends quote to the pipeline of every action under n that
+// prints a value, unless it already ends in raw or quote or is a string
+// constant on its own.
+func quoteActions(n parse.Node) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			quoteActions(c)
+		}
+	case *parse.IfNode:
+		quoteActions(n.List)
+		quoteActions(n.ElseList)
+	case *parse.RangeNode:
+		quoteActions(n.List)
+		quoteActions(n.ElseList)
+	case *parse.WithNode:
+		quoteActions(n.List)
+		quoteActions(n.ElseList)
+	case *parse.ActionNode:
+		pipe := n.Pipe
+		if len(pipe.Decl) > 0 || len(pipe.Cmds) == 0 {
+			return
+		}
+		last := pipe.Cmds[len(pipe.Cmds)-1]
+		if id, ok := last.Args[0].(*parse.IdentifierNode); ok && (id.Ident == "raw" || id.Ident == "quote") {
+			return
+		}
+		if _, ok := last.Args[0].(*parse.StringNode); ok && len(pipe.Cmds) == 1 && len(last.Args) == 1 {
+			return
+		}
+		quote := parse.NewIdentifier("quote").SetPos(n.Pos)
+		pipe.Cmds = append(pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{quote}})
+	}
+}
@@ -0,0 +1,126 @@
+package launcher
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRenderCmd(t *testing.T) {
+	t.Setenv("GOPENER_TEST_EDITOR", "vim")
+	branch := "main"
+	data := &cmdData{Name: "my app", Path: "/src/my app", SrcDir: "/src", branch: &branch}
+	tests := []struct {
+		shell string
+		cmd   string
+		want  string
+	}{
+		{"sh", "claude", "claude"},
+		// Single braces are left alone; {{"{{"}} is a literal {{.
+		{"sh", "awk '{print $1}'", "awk '{print $1}'"},
+		{"sh", `docker ps --format '{{"{{"}}.Names}} {{"{{"}}json .Ports}}'`, "docker ps --format '{{.Names}} {{json .Ports}}'"},
+		{"sh", `docker inspect --format '{{"{{"}}.Name}}' x`, `docker inspect --format '{{.Name}}' x`},
+		// Values are quoted unless raw.
+		{"sh", "echo {{.Name}} in {{ .SrcDir }}", "echo 'my app' in /src"},
+		{"sh", "git log {{.Branch}}", "git log main"},
+		{"sh", `{{env "GOPENER_TEST_EDITOR"}} .`, "vim ."},
+		{"sh", `x{{env "GOPENER_TEST_UNSET"}}`, "x''"},
+		{"sh", "cd {{quote .Path}}", `cd '/src/my app'`},
+		{"sh", `cd "{{raw .Path}}/sub"`, `cd "/src/my app/sub"`},
+		{"sh", `{{raw (env "GOPENER_TEST_EDITOR")}}`, "vim"},
+		{"fish", `echo {{.Name}}`, `echo 'my app'`},
+		// Anything text/template offers works, and what it prints is
+		// quoted too.
+		{"sh", `git log{{if .Branch}} {{.Branch}}{{end}}`, "git log main"},
+		{"sh", `claude{{with .Branch}}{{if eq . "main"}} --safe{{end}}{{end}}`, "claude --safe"},
+		{"sh", `echo {{printf "%s-dev" .Name}}`, `echo 'my app-dev'`},
+		{"sh", `echo {{.Path | raw}}`, `echo /src/my app`},
+		{"sh", `{{$n := .Name}}echo {{$n}}`, `echo 'my app'`},
+	}
+	for _, tt := range tests {
+		got, err := renderCmd(tt.cmd, tt.shell, data)
+		if err != nil {
+			t.Errorf("renderCmd(%q): %v", tt.cmd, err)
+		} else if got != tt.want {
+			t.Errorf("renderCmd(%q): got %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestCheckCmd(t *testing.T) {
+	tests := []struct {
+		cmd     string
+		wantErr string
+	}{
+		{"claude", ""},
+		{"code {{.Path}}", ""},
+		{"{{raw .Branch}} {{ quote .SrcDir }}", ""},
+		{`docker ps --format '{{"{{"}}.Names}}'`, ""},
+		{"{{if .Branch}}git log {{.Branch}}{{end}}", ""},
+		{"echo {{.Nmae}}", "can't evaluate field Nmae"},
+		{"cd {{.Nmae}} && x", "can't evaluate field Nmae"},
+		{"echo {{.name}}", "can't evaluate field name"},
+		{"docker ps --format '{{.Names}}'", `can't evaluate field Names in type *launcher.cmdData (write {{"{{"}} for a literal {{)`},
+		{"echo {{quote .Nmae}}", "can't evaluate field Nmae"},
+		{"{{env VAR}}", `function "VAR" not defined`},
+		{"{{raw}}", "wrong number of args for raw"},
+		{"{{if .Branch}}x", "unexpected EOF"},
+		{"{{ .Branch", "unclosed action"},
+		{"{{.Path}", "bad character"},
+		{"echo {{.Name", "unclosed action"},
+	}
+	for _, tt := range tests {
+		err := CheckCmd(tt.cmd)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("CheckCmd(%q): %v", tt.cmd, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("CheckCmd(%q): err = %v, want %q", tt.cmd, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", "-b", "topic", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	if got := (&cmdData{Path: repo}).Branch(); got != "topic" {
+		t.Errorf("repository: got %q", got)
+	}
+	if got := (&cmdData{Path: t.TempDir()}).Branch(); got != "" {
+		t.Errorf("not a repository: got %q", got)
+	}
+}
+
+func TestNewPlanRendersCmd(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	cfg := planFixture()
+	cfg.SrcDir = "/src"
+	cfg.Profiles[0].Cmd = "claude --name {{.Name}} --root {{.SrcDir}}"
+	cfg.Profiles[1].Cmd = "echo {{quote .Missing}}"
+
+	plan := NewPlan(cfg, cfg.Directories)
+	if len(plan.Commands) != 1 {
+		t.Fatalf("expected 1 command, got %+v", plan.Commands)
+	}
+	want := "claude --name web --root /src"
+	if c := plan.Commands[0]; c.Profile.Cmd != want || !strings.Contains(JoinArgv(c.Argv), want) {
+		t.Errorf("got cmd %q, argv %q", c.Profile.Cmd, c.Argv)
+	}
+
+	// A command that cannot be rendered is a warning for that pair.
+	var found bool
+	for _, w := range plan.Warnings {
+		if w.ProfileID == "p2" && strings.Contains(w.Message, "can't evaluate field Missing") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a warning for p2, got %+v", plan.Warnings)
+	}
+}
@@ -0,0 +1,29 @@
+package launcher
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// windowTitle is the title given to the window, tab or pane running p in
+// dir: p's title template with {name} replaced by the directory name and
+// {path}, or {dir} as in other templates, by its path. Control
+// characters are dropped so the title cannot end an escape sequence early.
+func windowTitle(dir config.DirConfig, p config.Profile) string {
+	r := strings.NewReplacer("{name}", dir.Name, "{path}", dir.Path, "{dir}", dir.Path, "{profile}", p.Label)
+	return strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) {
+			return -1
+		}
+		return c
+	}, r.Replace(p.TitleTemplate()))
+}
+
+// titleCommand is a shell command for p's shell that sets the title of the
+// terminal it runs in with an OSC escape, for terminals that have no title
+// flag. Programs started after it may still change the title themselves.
+func titleCommand(p config.Profile, title string) string {
+	return `printf '\033]0;%s\007' ` + quoterFor(profileShell(p).Path)(title)
+}
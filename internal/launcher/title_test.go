@@ -0,0 +1,49 @@
+package launcher
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestWindowTitle(t *testing.T) {
+	dir := config.DirConfig{Path: "/src/web", Name: "web"}
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"", "web · Claude"},
+		{"{profile}: {path}", "Claude: /src/web"},
+		{"{name} {name}", "web web"},
+		// {dir} is the path, as in every other template.
+		{"{name} in {dir}", "web in /src/web"},
+		{"plain", "plain"},
+		// Control characters could end the escape sequence early.
+		{"a\x07b\x1b]0;c\n", "ab]0;c"},
+	}
+	for _, tt := range tests {
+		p := config.Profile{Label: "Claude", Title: tt.title}
+		if got := windowTitle(dir, p); got != tt.want {
+			t.Errorf("windowTitle(%q): got %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+// TestTitleCommandRuns checks that each shell prints the OSC sequence with
+// the title intact.
+func TestTitleCommandRuns(t *testing.T) {
+	for _, shell := range shellsFor(t) {
+		for _, title := range append(trickyNames, "web · Claude", "100% done") {
+			p := config.Profile{Shell: &config.Shell{Path: shell}}
+			out, err := exec.Command(shell, "-c", titleCommand(p, title)).Output()
+			if err != nil {
+				t.Errorf("%s: %q: %v", shell, title, err)
+				continue
+			}
+			if want := "\x1b]0;" + title + "\x07"; string(out) != want {
+				t.Errorf("%s: got %q, want %q", shell, out, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// TerminalTmux selects the tmux backend instead of a terminal emulator.
+const TerminalTmux = "tmux"
+
+// defaultTmuxSession is the shared session used by the window layout.
+const defaultTmuxSession = "gopener"
+
+// Options gopener sets on the windows and panes it creates, so re-running a
+// launch finds them again even after programs rename their windows.
+const (
+	tmuxProfileOption = "@gopener_profile"
+	tmuxDirOption     = "@gopener_dir"
+)
+
+// tmuxRunner runs one tmux command and returns its trimmed stdout.
+type tmuxRunner func(args ...string) (string, error)
+
+func execTmux(args ...string) (string, error) {
+	out, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("tmux %s: %s", args[0], strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// tmuxBackend opens plan commands as tmux sessions, windows and panes.
+// Existing sessions are reused: a profile that already has a window (or
+// pane) created by gopener is skipped rather than opened twice.
+type tmuxBackend struct {
+	cfg    config.TmuxConfig
+	run    tmuxRunner
+	inTmux bool // gopener itself runs inside tmux, so it can switch client
+
+	sessions map[string]bool   // sessions known to exist
+	windows  map[string]string // directory path → window ID (window layout)
+	target   string            // what to switch the client to afterwards
+	targetOf config.DirConfig  // the directory target was opened for
+}
+
+func newTmuxBackend(cfg config.TmuxConfig, run tmuxRunner) *tmuxBackend {
+	return &tmuxBackend{
+		cfg:      cfg,
+		run:      run,
+		inTmux:   os.Getenv("TMUX") != "",
+		sessions: make(map[string]bool),
+		windows:  make(map[string]string),
+	}
+}
+
+func (b *tmuxBackend) windowLayout() bool {
+	return b.cfg.Layout == config.TmuxLayoutWindow
+}
+
+func (b *tmuxBackend) sharedSession() string {
+	if b.cfg.Session != "" {
+		return tmuxSessionName(b.cfg.Session)
+	}
+	return defaultTmuxSession
+}
+
+// launch runs the plan and, when gopener runs inside tmux, switches the
+// client to the first directory's session. Failing to switch is reported
+// against that directory alone, like a plan warning about it, since its
+// commands did launch.
+func (b *tmuxBackend) launch(ctx context.Context, plan Plan, progress func(Progress)) []Result {
+	results := plan.run(ctx, progress, withGUI(b.start))
+	if b.inTmux && b.target != "" {
+		if _, err := b.run("switch-client", "-t", b.target); err != nil {
+			err = fmt.Errorf("switching to its tmux session: %w", err)
+			results = append(results, Result{Dir: b.targetOf, Status: StatusFailed, Err: err})
+		}
+	}
+	return results
+}
+
+func (b *tmuxBackend) start(c Command) error {
+	if b.windowLayout() {
+		return b.startPane(c)
+	}
+	return b.startWindow(c)
+}
+
+// startWindow opens c as a window of the directory's own session.
+func (b *tmuxBackend) startWindow(c Command) error {
+	sess := tmuxSessionName(c.Dir.Name)
+	if b.target == "" {
+		b.target, b.targetOf = "="+sess, c.Dir
+	}
+
+	if !b.hasSession(sess) {
+		id, err := b.run("new-session", "-d", "-s", sess, "-c", c.Dir.Path, "-n", windowTitle(c.Dir, c.Profile),
+			"-P", "-F", "#{window_id}", tmuxCommand(c.Profile))
+		if err != nil {
+			return err
+		}
+		b.sessions[sess] = true
+		_, err = b.run("set-option", "-w", "-t", id, tmuxProfileOption, c.Profile.ID)
+		return err
+	}
+
+	open, err := b.run("list-windows", "-t", "="+sess, "-F", "#{"+tmuxProfileOption+"}")
+	if err != nil {
+		return err
+	}
+	if slices.Contains(strings.Split(open, "\n"), c.Profile.ID) {
+		return skipError("already open in tmux session " + sess)
+	}
+	id, err := b.run("new-window", "-d", "-t", "="+sess+":", "-c", c.Dir.Path, "-n", windowTitle(c.Dir, c.Profile),
+		"-P", "-F", "#{window_id}", tmuxCommand(c.Profile))
+	if err != nil {
+		return err
+	}
+	_, err = b.run("set-option", "-w", "-t", id, tmuxProfileOption, c.Profile.ID)
+	return err
+}
+
+// startPane opens c as a pane of the directory's window in the shared session.
+func (b *tmuxBackend) startPane(c Command) error {
+	sess := b.sharedSession()
+
+	win, err := b.findWindow(sess, c.Dir.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if b.target == "" {
+			b.target, b.targetOf = b.windows[c.Dir.Path], c.Dir
+		}
+	}()
+
+	if win == "" {
+		args := []string{"new-window", "-d", "-t", "=" + sess + ":"}
+		if !b.hasSession(sess) {
+			args = []string{"new-session", "-d", "-s", sess}
+		}
+		args = append(args, "-c", c.Dir.Path, "-n", c.Dir.Name, "-P", "-F", "#{window_id} #{pane_id}", tmuxCommand(c.Profile))
+		out, err := b.run(args...)
+		if err != nil {
+			return err
+		}
+		b.sessions[sess] = true
+		win, pane, _ := strings.Cut(out, " ")
+		b.windows[c.Dir.Path] = win
+		if _, err := b.run("set-option", "-w", "-t", win, tmuxDirOption, c.Dir.Path); err != nil {
+			return err
+		}
+		return b.markPane(pane, c)
+	}
+
+	open, err := b.run("list-panes", "-t", win, "-F", "#{"+tmuxProfileOption+"}")
+	if err != nil {
+		return err
+	}
+	if slices.Contains(strings.Split(open, "\n"), c.Profile.ID) {
+		return skipError("already open in tmux window " + sess + ":" + c.Dir.Name)
+	}
+	pane, err := b.run("split-window", "-d", "-t", win, "-c", c.Dir.Path, "-P", "-F", "#{pane_id}", tmuxCommand(c.Profile))
+	if err != nil {
+		return err
+	}
+	if err := b.markPane(pane, c); err != nil {
+		return err
+	}
+	_, err = b.run("select-layout", "-t", win, "tiled")
+	return err
+}
+
+// markPane records c's profile on pane and titles it, which shows in the
+// pane border when pane-border-status is on.
+func (b *tmuxBackend) markPane(pane string, c Command) error {
+	if _, err := b.run("set-option", "-p", "-t", pane, tmuxProfileOption, c.Profile.ID); err != nil {
+		return err
+	}
+	_, err := b.run("select-pane", "-t", pane, "-T", windowTitle(c.Dir, c.Profile))
+	return err
+}
+
+func (b *tmuxBackend) hasSession(sess string) bool {
+	if b.sessions[sess] {
+		return true
+	}
+	_, err := b.run("has-session", "-t", "="+sess)
+	b.sessions[sess] = err == nil
+	return err == nil
+}
+
+// findWindow returns the ID of the window gopener created for path in sess,
+// or "" if there is none yet.
+func (b *tmuxBackend) findWindow(sess, path string) (string, error) {
+	if win, ok := b.windows[path]; ok {
+		return win, nil
+	}
+	if !b.hasSession(sess) {
+		return "", nil
+	}
+	out, err := b.run("list-windows", "-t", "="+sess, "-F", "#{window_id} #{"+tmuxDirOption+"}")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		win, dir, _ := strings.Cut(line, " ")
+		if dir == path {
+			b.windows[path] = win
+			return win, nil
+		}
+	}
+	return "", nil
+}
+
+// tmuxArgv is the argv shown in plans and dry runs for the i-th profile of a
+// directory. The real launch may reuse existing sessions instead.
+func tmuxArgv(cfg config.TmuxConfig, dir config.DirConfig, p config.Profile, i int) []string {
+	if cfg.Layout == config.TmuxLayoutWindow {
+		sess := defaultTmuxSession
+		if cfg.Session != "" {
+			sess = tmuxSessionName(cfg.Session)
+		}
+		if i == 0 {
+			return []string{"tmux", "new-window", "-d", "-t", "=" + sess + ":", "-c", dir.Path, "-n", dir.Name, tmuxCommand(p)}
+		}
+		return []string{"tmux", "split-window", "-d", "-t", "=" + sess + ":" + dir.Name, "-c", dir.Path, tmuxCommand(p)}
+	}
+
+	sess := tmuxSessionName(dir.Name)
+	if i == 0 {
+		return []string{"tmux", "new-session", "-d", "-s", sess, "-c", dir.Path, "-n", windowTitle(dir, p), tmuxCommand(p)}
+	}
+	return []string{"tmux", "new-window", "-d", "-t", "=" + sess + ":", "-c", dir.Path, "-n", windowTitle(dir, p), tmuxCommand(p)}
+}
+
+// tmuxCommand is the shell command tmux runs for p, through p's shell so
+// its login and interactive settings apply. tmux hands it to its
+// default-shell, which is $SHELL unless tmux.conf says otherwise.
+func tmuxCommand(p config.Profile) string {
+	return joinFor(config.DefaultShell(), shellArgv(p, p.Cmd))
+}
+
+// tmuxSessionName makes name usable as a tmux session name, which may not
+// contain '.' or ':'.
+func tmuxSessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
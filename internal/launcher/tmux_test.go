@@ -0,0 +1,164 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestTmuxSessionName(t *testing.T) {
+	if got := tmuxSessionName("my.app:v2"); got != "my_app_v2" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestTmuxArgv(t *testing.T) {
+	t.Setenv("SHELL", "bash")
+	dir := config.DirConfig{Path: "/src/web", Name: "web.io"}
+	p := config.Profile{ID: "p1", Label: "Claude", Cmd: "claude"}
+
+	tests := []struct {
+		name string
+		cfg  config.TmuxConfig
+		i    int
+		want []string
+	}{
+		{"session first", config.TmuxConfig{}, 0,
+			[]string{"tmux", "new-session", "-d", "-s", "web_io", "-c", "/src/web", "-n", "web.io · Claude", "bash -c claude"}},
+		{"session next", config.TmuxConfig{}, 1,
+			[]string{"tmux", "new-window", "-d", "-t", "=web_io:", "-c", "/src/web", "-n", "web.io · Claude", "bash -c claude"}},
+		{"window first", config.TmuxConfig{Layout: config.TmuxLayoutWindow}, 0,
+			[]string{"tmux", "new-window", "-d", "-t", "=gopener:", "-c", "/src/web", "-n", "web.io", "bash -c claude"}},
+		{"window next", config.TmuxConfig{Layout: config.TmuxLayoutWindow, Session: "work"}, 1,
+			[]string{"tmux", "split-window", "-d", "-t", "=work:web.io", "-c", "/src/web", "bash -c claude"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tmuxArgv(tt.cfg, dir, p, tt.i); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// isolatedTmux returns a runner talking to a private tmux server that is
+// killed when the test ends.
+func isolatedTmux(t *testing.T) tmuxRunner {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	// Without a UTF-8 locale, or -u where there is none, tmux replaces the
+	// "·" of window titles.
+	t.Setenv("LC_ALL", "C.UTF-8")
+	t.Setenv("LANG", "C.UTF-8")
+	run := func(args ...string) (string, error) {
+		return execTmux(append([]string{"-u", "-L", "gopener-test"}, args...)...)
+	}
+	t.Cleanup(func() { _, _ = run("kill-server") })
+	return run
+}
+
+// launchTmux launches cfg with every profile sleeping, so its window stays
+// open for the test to inspect.
+func launchTmux(t *testing.T, cfg *config.Config, run tmuxRunner) []Result {
+	t.Helper()
+	for i := range cfg.Profiles {
+		cfg.Profiles[i].Cmd = "sleep 60"
+	}
+	plan := NewPlan(cfg, cfg.Directories)
+	b := newTmuxBackend(cfg.Tmux, run)
+	b.inTmux = false
+	return b.launch(context.Background(), plan, nil)
+}
+
+func statuses(results []Result) string {
+	var s []string
+	for _, r := range results {
+		s = append(s, r.Status.String())
+	}
+	return strings.Join(s, ",")
+}
+
+func TestTmuxSessionLayoutReusesSessions(t *testing.T) {
+	run := isolatedTmux(t)
+	cfg := testConfig(TerminalTmux, config.DirConfig{Path: "/tmp", Name: "tmp.dir", Enabled: true, ProfileIDs: []string{"p1", "p2"}})
+
+	if got := statuses(launchTmux(t, cfg, run)); got != "launched,launched" {
+		t.Fatalf("first launch: %s", got)
+	}
+	windows, err := run("list-windows", "-t", "=tmp_dir", "-F", "#{window_name}")
+	if err != nil {
+		t.Fatalf("list-windows: %v", err)
+	}
+	if windows != "tmp.dir · Claude\ntmp.dir · Shell" {
+		t.Errorf("windows: got %q", windows)
+	}
+
+	// A second launch finds both windows and opens nothing new.
+	results := launchTmux(t, cfg, run)
+	if got := statuses(results); got != "skipped,skipped" {
+		t.Fatalf("second launch: %s", got)
+	}
+	if !strings.Contains(results[0].Reason, "already open") {
+		t.Errorf("reason: got %q", results[0].Reason)
+	}
+}
+
+func TestTmuxWindowLayoutUsesPanes(t *testing.T) {
+	run := isolatedTmux(t)
+	cfg := testConfig(TerminalTmux, config.DirConfig{Path: "/tmp", Name: "tmp.dir", Enabled: true, ProfileIDs: []string{"p1", "p2"}})
+	cfg.Tmux = config.TmuxConfig{Layout: config.TmuxLayoutWindow, Session: "work"}
+	cfg.Profiles[1].Title = "{profile} in {path}"
+
+	if got := statuses(launchTmux(t, cfg, run)); got != "launched,launched" {
+		t.Fatalf("first launch: %s", got)
+	}
+	windows, err := run("list-windows", "-t", "=work", "-F", "#{window_name} #{window_panes}")
+	if err != nil {
+		t.Fatalf("list-windows: %v", err)
+	}
+	if windows != "tmp.dir 2" {
+		t.Errorf("windows: got %q", windows)
+	}
+	titles, err := run("list-panes", "-s", "-t", "=work", "-F", "#{pane_title}")
+	if err != nil {
+		t.Fatalf("list-panes: %v", err)
+	}
+	if titles != "tmp.dir · Claude\nShell in /tmp" {
+		t.Errorf("pane titles: got %q", titles)
+	}
+
+	if got := statuses(launchTmux(t, cfg, run)); got != "skipped,skipped" {
+		t.Fatalf("second launch: %s", got)
+	}
+}
+
+func TestTmuxSwitchFailureNamesDirectory(t *testing.T) {
+	cfg := testConfig(TerminalTmux, config.DirConfig{Path: "/tmp", Name: "tmp.dir", Enabled: true, ProfileIDs: []string{"p1", "p2"}})
+	plan := NewPlan(cfg, cfg.Directories)
+	b := newTmuxBackend(cfg.Tmux, func(args ...string) (string, error) {
+		switch args[0] {
+		case "has-session":
+			return "", errors.New("no session")
+		case "switch-client":
+			return "", errors.New("no current client")
+		}
+		return "@1", nil
+	})
+	b.inTmux = true
+
+	results := b.launch(context.Background(), plan, nil)
+	if got := statuses(results); got != "launched,launched,failed" {
+		t.Fatalf("statuses: %s", got)
+	}
+	if got := results[2].String(); got != "failed tmp.dir: switching to its tmux session: no current client" {
+		t.Errorf("got %q", got)
+	}
+}
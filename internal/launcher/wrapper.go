@@ -0,0 +1,48 @@
+package launcher
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// profileWrap composes the wrappers p uses into one argv prefix for dir,
+// nested in the order of cfg.Wrappers.
+func profileWrap(cfg *config.Config, dir config.DirConfig, p config.Profile) ([]string, error) {
+	for _, name := range p.Wrappers {
+		if w := cfg.FindWrapper(name); w == nil {
+			return nil, fmt.Errorf("wrapper %s does not exist", name)
+		} else if len(w.Argv) == 0 {
+			return nil, fmt.Errorf("wrapper %s has no command", name)
+		}
+	}
+	var wrap []string
+	for _, w := range cfg.Wrappers {
+		if !slices.Contains(p.Wrappers, w.Name) {
+			continue
+		}
+		for _, arg := range w.Argv {
+			wrap = append(wrap, strings.ReplaceAll(arg, "{dir}", dir.Path))
+		}
+	}
+	return wrap, nil
+}
+
+// ComposedArgv is the argv p runs as once its wrappers are composed around
+// its shell, with {dir} standing for the directory and without the exit
+// policy or environment. The profile editor shows it.
+func ComposedArgv(cfg *config.Config, p config.Profile) ([]string, error) {
+	wrap, err := profileWrap(cfg, config.DirConfig{Path: "{dir}"}, p)
+	if err != nil {
+		return nil, err
+	}
+	sh := cfg.ShellFor(p)
+	p.Shell, p.Wrap, p.Env = &sh, wrap, nil
+	if p.IsGUI() {
+		return guiArgv(p), nil
+	}
+	p.OnExit = ""
+	return shellArgv(p, p.Cmd), nil
+}
@@ -0,0 +1,86 @@
+package launcher
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestProfileWrap(t *testing.T) {
+	cfg := planFixture()
+	dir := config.DirConfig{Path: "/src/web"}
+	tests := []struct {
+		wrappers []string
+		want     []string
+		wantErr  bool
+	}{
+		{nil, nil, false},
+		{[]string{"nice"}, []string{"nice", "-n", "10"}, false},
+		// Nested in the configured order, whatever order the profile lists.
+		{[]string{"bwrap", "scope"}, []string{"systemd-run", "--user", "--scope", "bwrap", "--ro-bind", "/", "/", "--bind", "/src/web", "/src/web"}, false},
+		{[]string{"nice", "gone"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := profileWrap(cfg, dir, config.Profile{Wrappers: tt.wrappers})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.wrappers, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q:\n got %q\nwant %q", tt.wrappers, got, tt.want)
+		}
+	}
+
+	cfg.Wrappers = append(cfg.Wrappers, config.Wrapper{Name: "empty"})
+	if _, err := profileWrap(cfg, dir, config.Profile{Wrappers: []string{"empty"}}); err == nil {
+		t.Error("expected an error for a wrapper without a command")
+	}
+}
+
+func TestNewPlanComposesWrappers(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	cfg := planFixture()
+	cfg.Terminal = TerminalTmux
+	cfg.Profiles[0].Wrappers = []string{"nice", "bwrap"}
+	cfg.Profiles[1].Wrappers = []string{"missing"}
+	cfg.Directories[1].Activate = config.ActivateDirenv
+
+	plan := NewPlan(cfg, cfg.Directories)
+	if len(plan.Commands) != 1 {
+		t.Fatalf("expected 1 command, got %+v", plan.Commands)
+	}
+	// The profile's wrappers go around the directory's activation.
+	want := "nice -n 10 bwrap --ro-bind / / --bind /src/web /src/web direnv exec /src/web /bin/sh -c "
+	if cmd := plan.Commands[0].Argv[len(plan.Commands[0].Argv)-1]; !strings.HasPrefix(cmd, want) {
+		t.Errorf("tmux command:\n got %q\nwant prefix %q", cmd, want)
+	}
+	var found bool
+	for _, w := range plan.Warnings {
+		found = found || (w.ProfileID == "p2" && strings.Contains(w.Message, "wrapper missing does not exist"))
+	}
+	if !found {
+		t.Errorf("expected a warning for the missing wrapper, got %+v", plan.Warnings)
+	}
+}
+
+func TestComposedArgv(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	cfg := planFixture()
+	p := config.Profile{Cmd: "claude", Wrappers: []string{"bwrap"}, OnExit: config.ExitHold,
+		Env: map[string]string{"A": "b"}}
+
+	got, err := ComposedArgv(cfg, p)
+	if err != nil {
+		t.Fatalf("ComposedArgv: %v", err)
+	}
+	// Without the exit policy and environment, which would only add noise.
+	want := []string{"bwrap", "--ro-bind", "/", "/", "--bind", "{dir}", "{dir}", "/bin/zsh", "-c", "claude"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\n got %q\nwant %q", got, want)
+	}
+
+	if _, err := ComposedArgv(cfg, config.Profile{Cmd: "x", Wrappers: []string{"gone"}}); err == nil {
+		t.Error("expected an error for an unknown wrapper")
+	}
+}
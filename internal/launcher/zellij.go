@@ -0,0 +1,336 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+// TerminalZellij selects the zellij backend instead of a terminal emulator.
+const TerminalZellij = "zellij"
+
+// defaultZellijSession is the session used when none is configured.
+const defaultZellijSession = "gopener"
+
+// zellijRunner runs one zellij command and returns its trimmed stdout.
+type zellijRunner func(args ...string) (string, error)
+
+func execZellij(args ...string) (string, error) {
+	out, err := exec.Command("zellij", args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("zellij: %s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// zellijBackend opens every planned directory as a tab of one zellij session,
+// with a pane per profile. A session that does not exist yet is started from
+// a generated KDL layout in a new terminal window. Inside zellij, or when
+// the session already runs, missing directories are added as new tabs and
+// directories that already have a tab are skipped. Tabs added from outside
+// zellij to a session no client shows get a new terminal window attached
+// to it, so they do not open unseen.
+type zellijBackend struct {
+	cfg      config.ZellijConfig
+	run      zellijRunner
+	spawn    func(c Command, argv []string) error // starts the host terminal window, recorded for c
+	hostArgv func(host string, dir config.DirConfig, p config.Profile) []string
+
+	// current is the session gopener runs in, if any.
+	current string
+
+	session string
+	exists  bool
+	tabs    map[string]bool  // tab names present before the launch
+	started map[string]error // outcome per directory path, or for "" once the host window was opened
+}
+
+func newZellijBackend(cfg config.ZellijConfig, run zellijRunner) *zellijBackend {
+	return &zellijBackend{
+		cfg:      cfg,
+		run:      run,
+		spawn:    startArgv,
+		hostArgv: buildArgv,
+		current:  os.Getenv("ZELLIJ_SESSION_NAME"),
+	}
+}
+
+func (b *zellijBackend) launch(ctx context.Context, plan Plan, progress func(Progress)) []Result {
+	b.session = zellijSession(b.cfg)
+	if b.current != "" {
+		b.session = b.current
+	}
+	b.tabs = make(map[string]bool)
+	b.started = make(map[string]error)
+
+	b.exists = b.current != "" || b.sessionExists()
+	if b.exists {
+		names, err := b.action("query-tab-names")
+		if err != nil {
+			// Nothing can be added to a session whose tabs are unknown.
+			return plan.run(ctx, progress, func(Command) error { return err })
+		}
+		for _, n := range strings.Split(names, "\n") {
+			b.tabs[n] = true
+		}
+	}
+
+	return plan.run(ctx, progress, withGUI(func(c Command) error {
+		if b.tabs[c.Dir.Name] {
+			return skipError("already open in zellij session " + b.session)
+		}
+		if b.exists {
+			if err := b.addTab(plan, c.Dir); err != nil {
+				return err
+			}
+			return b.attach(c)
+		}
+		return b.startSession(plan, c)
+	}))
+}
+
+// sessionExists reports whether the configured session is running.
+func (b *zellijBackend) sessionExists() bool {
+	out, err := b.run("list-sessions", "--short", "--no-formatting")
+	if err != nil {
+		return false
+	}
+	for _, name := range strings.Split(out, "\n") {
+		if strings.TrimSpace(name) == b.session {
+			return true
+		}
+	}
+	return false
+}
+
+// action runs a zellij action against the target session.
+func (b *zellijBackend) action(args ...string) (string, error) {
+	if b.current == "" {
+		args = append([]string{"--session", b.session, "action"}, args...)
+	} else {
+		args = append([]string{"action"}, args...)
+	}
+	return b.run(args...)
+}
+
+// addTab opens dir as a new tab of the running session, once per directory.
+func (b *zellijBackend) addTab(plan Plan, dir config.DirConfig) error {
+	if err, ok := b.started[dir.Path]; ok {
+		return err
+	}
+	err := func() error {
+		path, err := writeZellijLayout(b.session+"-"+dir.Name, zellijTabLayout(plan, dir))
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+		_, err = b.action("new-tab", "--layout", path, "--name", dir.Name, "--cwd", dir.Path)
+		return err
+	}()
+	b.started[dir.Path] = err
+	return err
+}
+
+// startSession starts the session with every planned directory in a new
+// terminal window, once. The window is c's session.
+func (b *zellijBackend) startSession(plan Plan, c Command) error {
+	if err, ok := b.started[""]; ok {
+		return err
+	}
+	err := func() error {
+		path, err := writeZellijLayout(b.session, zellijLayout(plan))
+		if err != nil {
+			return err
+		}
+		return b.openHost(c, zellijStartArgv(b.session, path))
+	}()
+	b.started[""] = err
+	return err
+}
+
+// attach opens a terminal window attached to the running session, once,
+// unless gopener runs inside zellij or a client already shows the session.
+// The tabs are open either way, so a window that fails to open says so.
+func (b *zellijBackend) attach(c Command) error {
+	if b.current != "" {
+		return nil
+	}
+	if err, ok := b.started[""]; ok {
+		return err
+	}
+	var err error
+	if !b.hasClients() {
+		if err = b.openHost(c, []string{"zellij", "attach", b.session}); err != nil {
+			err = fmt.Errorf("added to zellij session %s, but could not attach: %w", b.session, err)
+		}
+	}
+	b.started[""] = err
+	return err
+}
+
+// hasClients reports whether a client shows the session. "list-clients"
+// prints a header and then a line per client; zellij releases without it
+// count as having none.
+func (b *zellijBackend) hasClients() bool {
+	out, err := b.action("list-clients")
+	if err != nil {
+		return false
+	}
+	return len(strings.Split(strings.TrimSpace(out), "\n")) > 1
+}
+
+// openHost opens a window of the host terminal running argv, recorded as
+// c's session.
+func (b *zellijBackend) openHost(c Command, argv []string) error {
+	host, err := resolveTerminal(b.cfg.Host)
+	if err != nil {
+		return err
+	}
+	if config.IsMultiplexer(host) {
+		return fmt.Errorf("zellij host terminal %s is not a terminal emulator", host)
+	}
+	home, _ := os.UserHomeDir()
+	hostArgv := b.hostArgv(host, config.DirConfig{Path: home}, config.Profile{Cmd: joinFor(config.DefaultShell(), argv)})
+	if hostArgv == nil {
+		return fmt.Errorf("terminal %s is not supported", host)
+	}
+	return b.spawn(c, hostArgv)
+}
+
+// zellijStartArgv starts a new named session from a layout file.
+func zellijStartArgv(session, layout string) []string {
+	return []string{"zellij", "--session", session, "--layout", layout}
+}
+
+func zellijSession(cfg config.ZellijConfig) string {
+	if cfg.Session != "" {
+		return cfg.Session
+	}
+	return defaultZellijSession
+}
+
+// zellijLayoutPath is where the layout for name is written.
+func zellijLayoutPath(name string) string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	name = strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(name)
+	return filepath.Join(base, "gopener", "zellij", name+".kdl")
+}
+
+// writeZellijLayout writes layout for name, readable only by the user. The
+// layout holds commands and paths; secrets stay in the profiles' env files.
+func writeZellijLayout(name, layout string) (string, error) {
+	path := zellijLayoutPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(layout), 0600); err != nil {
+		return "", err
+	}
+	// WriteFile keeps the mode of a layout written by an older version.
+	return path, os.Chmod(path, 0600)
+}
+
+// zellijArgv is the argv shown in plans and dry runs. Every item of a launch
+// ends up in the same session started from one layout.
+func zellijArgv(cfg config.ZellijConfig) []string {
+	session := zellijSession(cfg)
+	return zellijStartArgv(session, zellijLayoutPath(session))
+}
+
+// zellijLayout renders a KDL layout with a tab per directory in the plan and
+// a pane per profile, keeping the usual tab and status bars.
+func zellijLayout(plan Plan) string {
+	var sb strings.Builder
+	sb.WriteString("layout {\n")
+	sb.WriteString("    default_tab_template {\n")
+	sb.WriteString("        pane size=1 borderless=true {\n")
+	sb.WriteString("            plugin location=\"zellij:tab-bar\"\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("        children\n")
+	sb.WriteString("        pane size=2 borderless=true {\n")
+	sb.WriteString("            plugin location=\"zellij:status-bar\"\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("    }\n")
+
+	seen := make(map[string]bool)
+	for _, c := range plan.Commands {
+		if c.GUI() || seen[c.Dir.Path] {
+			continue
+		}
+		seen[c.Dir.Path] = true
+		fmt.Fprintf(&sb, "    tab name=%s cwd=%s {\n", kdlString(c.Dir.Name), kdlString(c.Dir.Path))
+		writeZellijPanes(&sb, plan, c.Dir, "        ")
+		sb.WriteString("    }\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// zellijTabLayout renders the panes for a single directory, for use with
+// "zellij action new-tab --layout".
+func zellijTabLayout(plan Plan, dir config.DirConfig) string {
+	var sb strings.Builder
+	sb.WriteString("layout {\n")
+	writeZellijPanes(&sb, plan, dir, "    ")
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func writeZellijPanes(sb *strings.Builder, plan Plan, dir config.DirConfig, indent string) {
+	for _, c := range plan.Commands {
+		if c.GUI() || c.Dir.Path != dir.Path {
+			continue
+		}
+		// zellij holds a pane whose command exited, showing its status, so
+		// only the close policy, the default, closes it.
+		argv := shellArgv(c.Profile, c.Profile.Cmd)
+		if c.Profile.ExitPolicy() == config.ExitHold {
+			argv = heldShellArgv(c.Profile, c.Profile.Cmd)
+		}
+		fmt.Fprintf(sb, "%spane name=%s cwd=%s command=%s {\n", indent, kdlString(windowTitle(dir, c.Profile)), kdlString(dir.Path), kdlString(argv[0]))
+		args := make([]string, len(argv)-1)
+		for i, a := range argv[1:] {
+			args[i] = kdlString(a)
+		}
+		fmt.Fprintf(sb, "%s    args %s\n", indent, strings.Join(args, " "))
+		if c.Profile.ExitPolicy() == config.ExitClose {
+			fmt.Fprintf(sb, "%s    close_on_exit true\n", indent)
+		}
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+}
+
+// kdlString quotes s as a KDL string literal.
+func kdlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,279 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func TestKDLString(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\dir`, `"C:\\dir"`},
+		{"a\nb\tc", `"a\nb\tc"`},
+	}
+	for _, tt := range tests {
+		if got := kdlString(tt.in); got != tt.want {
+			t.Errorf("kdlString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func zellijPlan(t *testing.T, cfg *config.Config) Plan {
+	t.Helper()
+	t.Setenv("SHELL", "/bin/zsh")
+	plan := NewPlan(cfg, cfg.Directories)
+	return plan
+}
+
+func TestZellijLayout(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	cfg.Profiles[1].Cmd = `echo "hi"`
+	got := zellijLayout(zellijPlan(t, cfg))
+	want := `layout {
+    default_tab_template {
+        pane size=1 borderless=true {
+            plugin location="zellij:tab-bar"
+        }
+        children
+        pane size=2 borderless=true {
+            plugin location="zellij:status-bar"
+        }
+    }
+    tab name="web" cwd="/src/web" {
+        pane name="web · Claude" cwd="/src/web" command="/bin/zsh" {
+            args "-c" "claude"
+            close_on_exit true
+        }
+        pane name="web · Shell" cwd="/src/web" command="/bin/zsh" {
+            args "-c" "echo \"hi\""
+            close_on_exit true
+        }
+    }
+    tab name="api" cwd="/src/api" {
+        pane name="api · Claude" cwd="/src/api" command="/bin/zsh" {
+            args "-c" "claude"
+            close_on_exit true
+        }
+    }
+}
+`
+	if got != want {
+		t.Errorf("layout:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestZellijTabLayout(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	got := zellijTabLayout(zellijPlan(t, cfg), cfg.Directories[1])
+	want := `layout {
+    pane name="api · Claude" cwd="/src/api" command="/bin/zsh" {
+        args "-c" "claude"
+        close_on_exit true
+    }
+}
+`
+	if got != want {
+		t.Errorf("layout:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestZellijExitPolicy(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	// The profile editor saves close, the default, as "".
+	cfg.Profiles[0].OnExit = ""
+	got := zellijTabLayout(zellijPlan(t, cfg), cfg.Directories[1])
+	want := `layout {
+    pane name="api · Claude" cwd="/src/api" command="/bin/zsh" {
+        args "-c" "claude"
+        close_on_exit true
+    }
+}
+`
+	if got != want {
+		t.Errorf("close:\n%s\nwant:\n%s", got, want)
+	}
+	cfg.Profiles[0].OnExit = config.ExitClose
+	if got := zellijTabLayout(zellijPlan(t, cfg), cfg.Directories[1]); got != want {
+		t.Errorf("explicit close:\n%s\nwant:\n%s", got, want)
+	}
+
+	// zellij holds the pane itself, so hold only reports the status.
+	cfg.Profiles[0].OnExit = config.ExitHold
+	got = zellijTabLayout(zellijPlan(t, cfg), cfg.Directories[1])
+	if strings.Contains(got, "close_on_exit") || strings.Contains(got, "read") || !strings.Contains(got, "exited with status") {
+		t.Errorf("hold:\n%s", got)
+	}
+}
+
+// fakeZellij records zellij invocations and answers the queries the backend
+// makes from canned output.
+type fakeZellij struct {
+	sessions string
+	tabs     string
+	tabsErr  error
+	clients  string
+	calls    [][]string
+}
+
+func (f *fakeZellij) run(args ...string) (string, error) {
+	f.calls = append(f.calls, args)
+	switch {
+	case args[0] == "list-sessions":
+		return f.sessions, nil
+	case slices.Contains(args, "query-tab-names"):
+		return f.tabs, f.tabsErr
+	case slices.Contains(args, "list-clients"):
+		return f.clients, nil
+	}
+	return "", nil
+}
+
+func newFakeZellijBackend(t *testing.T, cfg config.ZellijConfig, f *fakeZellij) (*zellijBackend, *[][]string) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	b := newZellijBackend(cfg, f.run)
+	b.current = ""
+	var spawned [][]string
+	b.spawn = func(_ Command, argv []string) error {
+		spawned = append(spawned, argv)
+		return nil
+	}
+	return b, &spawned
+}
+
+func TestZellijStartsNewSession(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	cfg.Zellij = config.ZellijConfig{Session: "work", Host: "xterm"}
+	plan := zellijPlan(t, cfg)
+	f := &fakeZellij{sessions: "other"}
+	b, spawned := newFakeZellijBackend(t, cfg.Zellij, f)
+
+	results := b.launch(context.Background(), plan, nil)
+	if got := statuses(results); got != "launched,launched,launched" {
+		t.Fatalf("statuses: %s", got)
+	}
+	if len(*spawned) != 1 {
+		t.Fatalf("expected one host terminal, got %q", *spawned)
+	}
+	if argv := strings.Join((*spawned)[0], " "); !strings.Contains(argv, "zellij --session work --layout") {
+		t.Errorf("host argv: got %q", argv)
+	}
+	layout, err := os.ReadFile(zellijLayoutPath("work"))
+	if err != nil {
+		t.Fatalf("layout not written: %v", err)
+	}
+	if !strings.Contains(string(layout), `tab name="api"`) {
+		t.Errorf("layout: got %s", layout)
+	}
+}
+
+func TestZellijAddsMissingTabs(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	plan := zellijPlan(t, cfg)
+	f := &fakeZellij{sessions: "gopener\nother", tabs: "web", clients: "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND\n1 terminal_2 bash"}
+	b, spawned := newFakeZellijBackend(t, cfg.Zellij, f)
+
+	results := b.launch(context.Background(), plan, nil)
+	if got := statuses(results); got != "skipped,skipped,launched" {
+		t.Fatalf("statuses: %s", got)
+	}
+	if !strings.Contains(results[0].Reason, "already open in zellij session gopener") {
+		t.Errorf("reason: got %q", results[0].Reason)
+	}
+	if len(*spawned) != 0 {
+		t.Errorf("no terminal should open for a session a client shows, got %q", *spawned)
+	}
+	want := []string{"--session", "gopener", "action", "new-tab", "--layout", zellijLayoutPath("gopener-api"), "--name", "api", "--cwd", "/src/api"}
+	var found bool
+	for _, call := range f.calls {
+		found = found || reflect.DeepEqual(call, want)
+	}
+	if !found {
+		t.Errorf("no new-tab call %q in %q", want, f.calls)
+	}
+	// The tab layout only lives until zellij has read it.
+	if _, err := os.Stat(zellijLayoutPath("gopener-api")); err == nil {
+		t.Error("tab layout left behind")
+	}
+}
+
+func TestZellijFailsEachCommandWhenTabsAreUnknown(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	plan := zellijPlan(t, cfg)
+	f := &fakeZellij{sessions: "gopener", tabsErr: errors.New("no session")}
+	b, _ := newFakeZellijBackend(t, cfg.Zellij, f)
+
+	results := b.launch(context.Background(), plan, nil)
+	if got := statuses(results); got != "failed,failed,failed" {
+		t.Fatalf("statuses: %s", got)
+	}
+	for _, r := range results {
+		if r.Dir.Name == "" || r.Err == nil || r.Err.Error() != "no session" {
+			t.Errorf("result: got %v", r)
+		}
+	}
+}
+
+func TestZellijAttachesUnseenSession(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	cfg.Zellij.Host = "xterm"
+	plan := zellijPlan(t, cfg)
+	f := &fakeZellij{sessions: "gopener", clients: "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND"}
+	b, spawned := newFakeZellijBackend(t, cfg.Zellij, f)
+
+	if got := statuses(b.launch(context.Background(), plan, nil)); got != "launched,launched,launched" {
+		t.Fatalf("statuses: %s", got)
+	}
+	// One window for both new tabs.
+	if len(*spawned) != 1 || !strings.Contains(strings.Join((*spawned)[0], " "), "zellij attach gopener") {
+		t.Errorf("expected one window attaching to the session, got %q", *spawned)
+	}
+}
+
+func TestZellijInsideSessionUsesCurrent(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	plan := zellijPlan(t, cfg)
+	f := &fakeZellij{}
+	b, _ := newFakeZellijBackend(t, cfg.Zellij, f)
+	b.current = "mine"
+
+	if got := statuses(b.launch(context.Background(), plan, nil)); got != "launched,launched,launched" {
+		t.Fatalf("statuses: %s", got)
+	}
+	for _, call := range f.calls {
+		if call[0] != "action" {
+			t.Errorf("inside zellij every call should be an action on the current session, got %q", call)
+		}
+	}
+	// One new-tab per directory, not per profile.
+	tabs := 0
+	for _, call := range f.calls {
+		if slices.Contains(call, "new-tab") {
+			tabs++
+		}
+	}
+	if tabs != 2 {
+		t.Errorf("new-tab calls: got %d, want 2", tabs)
+	}
+}
+
+func TestZellijHostFailureFailsEveryItem(t *testing.T) {
+	cfg := testConfig(TerminalZellij, testDir("web", "p1", "p2"), testDir("api", "p1"))
+	cfg.Zellij.Host = "xterm"
+	plan := zellijPlan(t, cfg)
+	b, _ := newFakeZellijBackend(t, cfg.Zellij, &fakeZellij{})
+	b.spawn = func(Command, []string) error { return errors.New("boom") }
+
+	if got := statuses(b.launch(context.Background(), plan, nil)); got != "failed,failed,failed" {
+		t.Errorf("statuses: %s", got)
+	}
+}
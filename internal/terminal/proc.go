@@ -0,0 +1,86 @@
+package terminal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcRoot is the procfs mount Detect reads the process tree from.
+var ProcRoot = "/proc"
+
+// maxDepth bounds the walk up the process tree.
+const maxDepth = 64
+
+// commLen is the length the kernel truncates process names in comm to.
+const commLen = 15
+
+// Parent walks up the process tree from pid, reading procRoot/<pid>/stat,
+// comm and exe, and returns the first known terminal emulator it passes,
+// or "" when it reaches init without finding one. Shells and other
+// processes in between are skipped. A multiplexer's server is not a child
+// of the terminal showing it, so inside tmux or zellij the walk reaches
+// init and Detect falls back to the environment.
+func Parent(procRoot string, pid int) string {
+	for depth := 0; pid > 1 && depth < maxDepth; depth++ {
+		dir := filepath.Join(procRoot, strconv.Itoa(pid))
+		if t, ok := matchProcess(processNames(dir)); ok {
+			return t.Name
+		}
+		ppid, err := parentPID(dir)
+		if err != nil || ppid == pid {
+			return ""
+		}
+		pid = ppid
+	}
+	return ""
+}
+
+// processNames returns the base name of a process's executable and its
+// comm, either of which may be missing.
+func processNames(dir string) (exe, comm string) {
+	if target, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		exe = filepath.Base(strings.TrimSuffix(target, " (deleted)"))
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		comm = strings.TrimSpace(string(b))
+	}
+	return exe, comm
+}
+
+// matchProcess finds the registry entry a process with the given names
+// belongs to. comm is matched allowing for the kernel's truncation.
+func matchProcess(exe, comm string) (Terminal, bool) {
+	for _, t := range All {
+		if t.NoCommand {
+			continue
+		}
+		for _, name := range append(append([]string(nil), t.Binaries...), t.Processes...) {
+			if exe == name || comm == name || (len(comm) == commLen && strings.HasPrefix(name, comm)) {
+				return t, true
+			}
+		}
+	}
+	return Terminal{}, false
+}
+
+// parentPID reads the parent PID from dir/stat. The process name in the
+// second field may contain spaces and parentheses, so fields are counted
+// from the last ')'.
+func parentPID(dir string) (int, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	s := string(b)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return 0, os.ErrInvalid
+	}
+	fields := strings.Fields(s[i+1:])
+	if len(fields) < 2 {
+		return 0, os.ErrInvalid
+	}
+	return strconv.Atoi(fields[1])
+}
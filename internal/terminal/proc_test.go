@@ -0,0 +1,136 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// proc is one process in a fake /proc tree.
+type proc struct {
+	pid, ppid int
+	comm      string
+	exe       string // symlink target, "" for none (e.g. another user's process)
+}
+
+func fakeProc(t *testing.T, procs ...proc) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, p := range procs {
+		dir := filepath.Join(root, strconv.Itoa(p.pid))
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		stat := fmt.Sprintf("%d (%s) S %d %d 0 0 -1 4194304\n", p.pid, p.comm, p.ppid, p.ppid)
+		if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "comm"), []byte(p.comm+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if p.exe != "" {
+			if err := os.Symlink(p.exe, filepath.Join(dir, "exe")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return root
+}
+
+func TestParent(t *testing.T) {
+	tests := []struct {
+		name  string
+		procs []proc
+		want  string
+	}{
+		{
+			"konsole running bash",
+			[]proc{
+				{pid: 300, ppid: 200, comm: "gopener", exe: "/usr/bin/gopener"},
+				{pid: 200, ppid: 100, comm: "bash", exe: "/usr/bin/bash"},
+				{pid: 100, ppid: 1, comm: "konsole", exe: "/usr/bin/konsole"},
+			},
+			"konsole",
+		},
+		{
+			"gnome-terminal server with truncated comm and no exe",
+			[]proc{
+				{pid: 300, ppid: 200, comm: "gopener", exe: "/usr/bin/gopener"},
+				{pid: 200, ppid: 100, comm: "zsh", exe: "/usr/bin/zsh"},
+				{pid: 100, ppid: 1, comm: "gnome-terminal-"},
+			},
+			"gnome-terminal",
+		},
+		{
+			"tmux server detached from any terminal",
+			[]proc{
+				{pid: 400, ppid: 300, comm: "gopener"},
+				{pid: 300, ppid: 250, comm: "fish"},
+				{pid: 250, ppid: 1, comm: "tmux: server"},
+			},
+			"",
+		},
+		{
+			"wezterm-gui",
+			[]proc{
+				{pid: 300, ppid: 200, comm: "gopener"},
+				{pid: 200, ppid: 100, comm: "bash"},
+				{pid: 100, ppid: 1, comm: "wezterm-gui", exe: "/opt/wezterm/wezterm-gui"},
+			},
+			"wezterm",
+		},
+		{
+			"name with spaces and parens",
+			[]proc{
+				{pid: 300, ppid: 200, comm: "my (odd) tool"},
+				{pid: 200, ppid: 1, comm: "kitty", exe: "/usr/lib/kitty/kitty (deleted)"},
+			},
+			"kitty",
+		},
+		{
+			"warp is never picked",
+			[]proc{
+				{pid: 300, ppid: 200, comm: "bash"},
+				{pid: 200, ppid: 1, comm: "warp-terminal"},
+			},
+			"",
+		},
+		{
+			"missing parent",
+			[]proc{
+				{pid: 300, ppid: 200, comm: "bash"},
+			},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := fakeProc(t, tt.procs...)
+			if got := Parent(root, tt.procs[0].pid); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParentStopsOnCycle(t *testing.T) {
+	root := fakeProc(t,
+		proc{pid: 10, ppid: 20, comm: "a"},
+		proc{pid: 20, ppid: 10, comm: "b"},
+	)
+	if got := Parent(root, 10); got != "" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDetectPrefersParentOverEnv(t *testing.T) {
+	clearEnv(t)
+	fakePath(t, "kitty", "konsole")
+	t.Setenv("KITTY_WINDOW_ID", "1") // leaked from the kitty konsole was started from
+	ProcRoot = fakeProc(t, proc{pid: os.Getppid(), ppid: 1, comm: "konsole"})
+	if got := Detect(); got != "konsole" {
+		t.Errorf("got %q, want konsole", got)
+	}
+}
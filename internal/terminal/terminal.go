@@ -0,0 +1,262 @@
+// Package terminal is the registry of terminal emulators gopener knows how
+// to drive on Linux. Detection in config and argv building in launcher both
+// read it, so a terminal that can be selected can also be launched.
+package terminal
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Terminal describes how to open a window of one emulator running a command.
+//
+// Flags ending in "=" take their value in the same argument
+// ("--title=web"); other flags take it as the next argument.
+type Terminal struct {
+	Name      string   // name stored in the config
+	Binaries  []string // executables to look for, in order of preference
+	Args      []string // fixed arguments right after the binary, e.g. wezterm's "start"
+	DirFlag   string   // flag setting the working directory, "" if there is none
+	TitleFlag string   // flag setting the window title, "" if there is none
+	Exec      []string // arguments introducing the command; empty when it simply follows
+	HoldFlag  string   // option keeping the window open after the command exits, "" if there is none
+	Env       []string // variables set inside the terminal, used to detect it
+	Processes []string // process names other than Binaries, e.g. a GUI server
+	NoCommand bool     // cannot be told what to run; never offered or detected
+}
+
+// All lists the known terminals in order of preference for detection.
+var All = []Terminal{
+	{
+		Name:      "ghostty",
+		Binaries:  []string{"ghostty"},
+		DirFlag:   "--working-directory=",
+		TitleFlag: "--title=",
+		Exec:      []string{"-e"},
+		HoldFlag:  "--wait-after-command=true",
+		Env:       []string{"GHOSTTY_RESOURCES_DIR"},
+	},
+	{
+		Name:      "wezterm",
+		Binaries:  []string{"wezterm"},
+		Args:      []string{"start"},
+		DirFlag:   "--cwd",
+		Exec:      []string{"--"},
+		Env:       []string{"WEZTERM_PANE"},
+		Processes: []string{"wezterm-gui"},
+	},
+	{
+		Name:      "kitty",
+		Binaries:  []string{"kitty"},
+		DirFlag:   "--directory",
+		TitleFlag: "--title",
+		HoldFlag:  "--hold",
+		Env:       []string{"KITTY_WINDOW_ID"},
+	},
+	{
+		Name:      "alacritty",
+		Binaries:  []string{"alacritty"},
+		DirFlag:   "--working-directory",
+		TitleFlag: "--title",
+		Exec:      []string{"-e"},
+		HoldFlag:  "--hold",
+		Env:       []string{"ALACRITTY_SOCKET", "ALACRITTY_LOG"},
+	},
+	{
+		Name:     "konsole",
+		Binaries: []string{"konsole"},
+		DirFlag:  "--workdir",
+		Exec:     []string{"-e"},
+		HoldFlag: "--hold",
+		Env:      []string{"KONSOLE_VERSION"},
+	},
+	{
+		// gnome-terminal ignores --title since 3.x.
+		Name:      "gnome-terminal",
+		Binaries:  []string{"gnome-terminal"},
+		DirFlag:   "--working-directory=",
+		Exec:      []string{"--"},
+		Env:       []string{"GNOME_TERMINAL_SCREEN"},
+		Processes: []string{"gnome-terminal-server"},
+	},
+	{
+		Name:      "xfce4-terminal",
+		Binaries:  []string{"xfce4-terminal"},
+		DirFlag:   "--working-directory=",
+		TitleFlag: "--title=",
+		Exec:      []string{"-x"},
+		HoldFlag:  "--hold",
+	},
+	{
+		Name:      "mate-terminal",
+		Binaries:  []string{"mate-terminal"},
+		DirFlag:   "--working-directory=",
+		TitleFlag: "--title=",
+		Exec:      []string{"-x"},
+	},
+	{
+		Name:      "terminator",
+		Binaries:  []string{"terminator"},
+		DirFlag:   "--working-directory=",
+		TitleFlag: "--title=",
+		Exec:      []string{"-x"},
+		Env:       []string{"TERMINATOR_UUID"},
+	},
+	{
+		Name:      "urxvt",
+		Binaries:  []string{"urxvt", "rxvt-unicode"},
+		DirFlag:   "-cd",
+		TitleFlag: "-title",
+		Exec:      []string{"-e"},
+		HoldFlag:  "-hold",
+		Processes: []string{"urxvtd"},
+	},
+	{
+		Name:      "xterm",
+		Binaries:  []string{"xterm"},
+		TitleFlag: "-T",
+		Exec:      []string{"-e"},
+		HoldFlag:  "-hold",
+	},
+	{
+		// Warp opens a plain shell and has no option to run a command.
+		Name:      "warp-terminal",
+		Binaries:  []string{"warp-terminal"},
+		NoCommand: true,
+	},
+}
+
+// lookPath is exec.LookPath, replaced in tests.
+var lookPath = exec.LookPath
+
+// Lookup returns the registry entry called name.
+func Lookup(name string) (Terminal, bool) {
+	for _, t := range All {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Terminal{}, false
+}
+
+// Generic describes an unknown terminal by guessing the common "-e" form.
+func Generic(name string) Terminal {
+	return Terminal{Name: name, Binaries: []string{name}, Exec: []string{"-e"}}
+}
+
+// Binary returns the first of t's binaries that is installed, or the first
+// one when none is.
+func (t Terminal) Binary() string {
+	for _, b := range t.Binaries {
+		if _, err := lookPath(b); err == nil {
+			return b
+		}
+	}
+	return t.Binaries[0]
+}
+
+// Installed reports whether any of t's binaries is on PATH.
+func (t Terminal) Installed() bool {
+	for _, b := range t.Binaries {
+		if _, err := lookPath(b); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Argv returns the argv opening a window of t in dir, titled title, running
+// command. dir and title are dropped when t has no flag for them; callers
+// check DirFlag to change directory some other way.
+func (t Terminal) Argv(dir, title string, command []string) []string {
+	return t.argv(dir, title, command, false)
+}
+
+// HeldArgv is Argv for a window that stays open after command exits. It
+// panics when t has no HoldFlag.
+func (t Terminal) HeldArgv(dir, title string, command []string) []string {
+	if t.HoldFlag == "" {
+		panic("terminal: " + t.Name + " has no hold flag")
+	}
+	return t.argv(dir, title, command, true)
+}
+
+func (t Terminal) argv(dir, title string, command []string, hold bool) []string {
+	argv := append([]string{t.Binary()}, t.Args...)
+	if hold {
+		argv = append(argv, t.HoldFlag)
+	}
+	if dir != "" {
+		argv = appendFlag(argv, t.DirFlag, dir)
+	}
+	if title != "" {
+		argv = appendFlag(argv, t.TitleFlag, title)
+	}
+	argv = append(argv, t.Exec...)
+	return append(argv, command...)
+}
+
+func appendFlag(argv []string, flag, value string) []string {
+	switch {
+	case flag == "":
+		return argv
+	case strings.HasSuffix(flag, "="):
+		return append(argv, flag+value)
+	default:
+		return append(argv, flag, value)
+	}
+}
+
+// Installed returns the installed terminals that can run commands, in
+// order of preference.
+func Installed() []Terminal {
+	var installed []Terminal
+	for _, t := range All {
+		if !t.NoCommand && t.Installed() {
+			installed = append(installed, t)
+		}
+	}
+	return installed
+}
+
+// Detect picks a terminal: $TERMINAL when it is installed, then the
+// terminal gopener is running in, judged first by its parent processes and
+// then by the environment, then the first installed one. It returns "" when
+// nothing is found.
+func Detect() string {
+	if name := os.Getenv("TERMINAL"); name != "" {
+		if _, err := lookPath(name); err == nil {
+			return name
+		}
+	}
+	if name := Parent(ProcRoot, os.Getppid()); name != "" {
+		return name
+	}
+	if name := Running(); name != "" {
+		return name
+	}
+	if installed := Installed(); len(installed) > 0 {
+		return installed[0].Name
+	}
+	return ""
+}
+
+// Running returns the known terminal gopener runs inside of, judged by the
+// environment, or "".
+func Running() string {
+	if t, ok := Lookup(os.Getenv("TERM_PROGRAM")); ok && !t.NoCommand && t.Installed() {
+		return t.Name
+	}
+	for _, t := range All {
+		if t.NoCommand {
+			continue
+		}
+		for _, v := range t.Env {
+			if os.Getenv(v) != "" {
+				return t.Name
+			}
+		}
+	}
+	return ""
+}
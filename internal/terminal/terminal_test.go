@@ -0,0 +1,171 @@
+package terminal
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// fakePath makes exactly the given binaries look installed.
+func fakePath(t *testing.T, installed ...string) {
+	t.Helper()
+	orig := lookPath
+	lookPath = func(name string) (string, error) {
+		for _, b := range installed {
+			if b == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = orig })
+}
+
+// clearEnv unsets every variable detection looks at and hides the real
+// process tree.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	orig := ProcRoot
+	ProcRoot = t.TempDir()
+	t.Cleanup(func() { ProcRoot = orig })
+	t.Setenv("TERMINAL", "")
+	t.Setenv("TERM_PROGRAM", "")
+	for _, term := range All {
+		for _, v := range term.Env {
+			t.Setenv(v, "")
+		}
+	}
+}
+
+func TestArgv(t *testing.T) {
+	fakePath(t)
+	cmd := []string{"bash", "-c", "claude"}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"ghostty", []string{"ghostty", "--working-directory=/src", "--title=T", "-e", "bash", "-c", "claude"}},
+		{"wezterm", []string{"wezterm", "start", "--cwd", "/src", "--", "bash", "-c", "claude"}},
+		{"kitty", []string{"kitty", "--directory", "/src", "--title", "T", "bash", "-c", "claude"}},
+		{"alacritty", []string{"alacritty", "--working-directory", "/src", "--title", "T", "-e", "bash", "-c", "claude"}},
+		{"konsole", []string{"konsole", "--workdir", "/src", "-e", "bash", "-c", "claude"}},
+		{"gnome-terminal", []string{"gnome-terminal", "--working-directory=/src", "--", "bash", "-c", "claude"}},
+		{"xfce4-terminal", []string{"xfce4-terminal", "--working-directory=/src", "--title=T", "-x", "bash", "-c", "claude"}},
+		{"mate-terminal", []string{"mate-terminal", "--working-directory=/src", "--title=T", "-x", "bash", "-c", "claude"}},
+		{"terminator", []string{"terminator", "--working-directory=/src", "--title=T", "-x", "bash", "-c", "claude"}},
+		{"urxvt", []string{"urxvt", "-cd", "/src", "-title", "T", "-e", "bash", "-c", "claude"}},
+		{"xterm", []string{"xterm", "-T", "T", "-e", "bash", "-c", "claude"}},
+		{"warp-terminal", []string{"warp-terminal", "bash", "-c", "claude"}},
+	}
+
+	tested := make(map[string]bool)
+	for _, tt := range tests {
+		tested[tt.name] = true
+		t.Run(tt.name, func(t *testing.T) {
+			term, ok := Lookup(tt.name)
+			if !ok {
+				t.Fatalf("%s is not in the registry", tt.name)
+			}
+			if got := term.Argv("/src", "T", cmd); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+	for _, term := range All {
+		if !tested[term.Name] {
+			t.Errorf("registry entry %s has no test case", term.Name)
+		}
+	}
+}
+
+func TestArgvOmitsEmptyValues(t *testing.T) {
+	fakePath(t)
+	term, _ := Lookup("kitty")
+	want := []string{"kitty", "sh"}
+	if got := term.Argv("", "", []string{"sh"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRegistryEntries(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, term := range All {
+		if seen[term.Name] {
+			t.Errorf("duplicate entry %s", term.Name)
+		}
+		seen[term.Name] = true
+		if len(term.Binaries) == 0 {
+			t.Errorf("%s has no binaries", term.Name)
+		}
+	}
+}
+
+func TestBinaryPrefersInstalled(t *testing.T) {
+	term, _ := Lookup("urxvt")
+
+	fakePath(t, "rxvt-unicode")
+	if got := term.Binary(); got != "rxvt-unicode" {
+		t.Errorf("got %q, want rxvt-unicode", got)
+	}
+
+	fakePath(t)
+	if got := term.Binary(); got != "urxvt" {
+		t.Errorf("nothing installed: got %q, want urxvt", got)
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	want := []string{"foot", "-e", "sh"}
+	if got := Generic("foot").Argv("/src", "T", []string{"sh"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		env       map[string]string
+		want      string
+	}{
+		{"nothing installed", nil, nil, ""},
+		{"preference order", []string{"xterm", "kitty", "alacritty"}, nil, "kitty"},
+		{"never picks warp", []string{"warp-terminal", "xterm"}, nil, "xterm"},
+		{"TERMINAL wins", []string{"kitty", "foot"}, map[string]string{"TERMINAL": "foot"}, "foot"},
+		{"TERMINAL not installed", []string{"xterm"}, map[string]string{"TERMINAL": "foot"}, "xterm"},
+		{"TERM_PROGRAM", []string{"kitty", "wezterm"}, map[string]string{"TERM_PROGRAM": "wezterm"}, "wezterm"},
+		{"running in alacritty", []string{"kitty", "alacritty"}, map[string]string{"ALACRITTY_LOG": "/tmp/log"}, "alacritty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fakePath(t, tt.installed...)
+			if got := Detect(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstalled(t *testing.T) {
+	fakePath(t, "xterm", "warp-terminal", "ghostty")
+	var names []string
+	for _, term := range Installed() {
+		names = append(names, term.Name)
+	}
+	if want := []string{"ghostty", "xterm"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %q, want %q", names, want)
+	}
+}
+
+func TestHeldArgv(t *testing.T) {
+	fakePath(t)
+	term, _ := Lookup("xterm")
+	want := []string{"xterm", "-hold", "-T", "T", "-e", "sh"}
+	if got := term.HeldArgv("/src", "T", []string{"sh"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
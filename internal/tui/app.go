@@ -6,6 +6,7 @@ import (
 	"github.com/jimbo/gopener/internal/launcher"
 	"github.com/jimbo/gopener/internal/scanner"
 	mainscreen "github.com/jimbo/gopener/internal/tui/screens/main"
+	"github.com/jimbo/gopener/internal/tui/screens/plan"
 	"github.com/jimbo/gopener/internal/tui/screens/profiles"
 	"github.com/jimbo/gopener/internal/tui/screens/settings"
 	"github.com/jimbo/gopener/internal/tui/screens/setup"
@@ -18,6 +19,7 @@ const (
 	screenMain
 	screenProfiles
 	screenSettings
+	screenPlan
 )
 
 type App struct {
@@ -28,6 +30,7 @@ type App struct {
 	main     mainscreen.Model
 	profiles profiles.Model
 	settings settings.Model
+	plan     plan.Model
 }
 
 func NewApp(cfg *config.Config, l launcher.Launcher) *App {
@@ -52,6 +55,11 @@ func NewApp(cfg *config.Config, l launcher.Launcher) *App {
 }
 
 func (a *App) Init() tea.Cmd {
+	// Session indicators keep refreshing whichever screen is shown.
+	return tea.Batch(mainscreen.WatchSessions(), a.initScreen())
+}
+
+func (a *App) initScreen() tea.Cmd {
 	switch a.screen {
 	case screenSetup:
 		return a.setup.Init()
@@ -61,11 +69,22 @@ func (a *App) Init() tea.Cmd {
 		return a.profiles.Init()
 	case screenSettings:
 		return a.settings.Init()
+	case screenPlan:
+		return a.plan.Init()
 	}
 	return nil
 }
 
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// A background launch or stop keeps reporting to the main screen even
+	// while another screen is shown, as do session refreshes.
+	switch msg.(type) {
+	case mainscreen.ProgressMsg, mainscreen.StartedMsg, mainscreen.SessionsMsg, mainscreen.StoppedMsg:
+		updated, cmd := a.main.Update(msg)
+		a.main = updated
+		return a, cmd
+	}
+
 	switch a.screen {
 	case screenSetup:
 		updated, cmd := a.setup.Update(msg)
@@ -97,6 +116,11 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.screen = screenSettings
 			return a, a.settings.Init()
 		}
+		if goPlan, ok := msg.(mainscreen.GoPlanMsg); ok {
+			a.plan = plan.New(a.cfg, goPlan.DryRun)
+			a.screen = screenPlan
+			return a, a.plan.Init()
+		}
 		return a, cmd
 
 	case screenProfiles:
@@ -104,7 +128,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.profiles = updated
 		if _, ok := msg.(profiles.BackMsg); ok {
 			// Refresh main screen in case profiles changed.
-			a.main = mainscreen.New(a.cfg, a.launcher)
+			a.main = a.main.Refresh()
 			a.screen = screenMain
 			return a, a.main.Init()
 		}
@@ -115,11 +139,26 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.settings = updated
 		if _, ok := msg.(settings.GoBackMsg); ok {
 			// Refresh main screen in case settings changed.
-			a.main = mainscreen.New(a.cfg, a.launcher)
+			a.main = a.main.Refresh()
 			a.screen = screenMain
 			return a, a.main.Init()
 		}
 		return a, cmd
+
+	case screenPlan:
+		updated, cmd := a.plan.Update(msg)
+		a.plan = updated
+		switch msg := msg.(type) {
+		case plan.BackMsg:
+			a.screen = screenMain
+			return a, nil
+		case plan.ConfirmMsg:
+			// The main screen runs the launch and shows its progress.
+			a.screen = screenMain
+			a.main, cmd = a.main.Update(mainscreen.LaunchMsg{Dirs: msg.Dirs, DryRun: msg.DryRun})
+			return a, cmd
+		}
+		return a, cmd
 	}
 	return a, nil
 }
@@ -134,6 +173,8 @@ func (a *App) View() string {
 		return a.profiles.View()
 	case screenSettings:
 		return a.settings.View()
+	case screenPlan:
+		return a.plan.View()
 	}
 	return ""
 }
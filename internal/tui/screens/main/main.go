@@ -1,8 +1,11 @@
 package mainscreen
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -16,10 +19,21 @@ import (
 
 type screenMode int
 
+// assignField is an assign overlay field edited in a text input.
+type assignField int
+
+const (
+	editNone assignField = iota
+	editEnv
+	editWrapper
+)
+
 const (
 	modeList      screenMode = iota
 	modeAssign               // profile assignment overlay
 	modeChangeSrc            // inline src dir edit
+	modePick                 // one-profile launch menu for the cursor's directory
+	modeStop                 // menu of the cursor's directory's running sessions
 )
 
 // GoProfilesMsg switches to the profiles screen.
@@ -28,8 +42,58 @@ type GoProfilesMsg struct{}
 // GoSettingsMsg switches to the settings screen.
 type GoSettingsMsg struct{}
 
-// StartedMsg is sent after launching.
-type StartedMsg struct{ Err error }
+// GoPlanMsg switches to the launch plan screen.
+type GoPlanMsg struct{ DryRun bool }
+
+// LaunchMsg starts launching Dirs in the background.
+type LaunchMsg struct {
+	Dirs   []config.DirConfig
+	DryRun bool
+}
+
+// ProgressMsg is streamed while a launch is running.
+type ProgressMsg struct {
+	Progress launcher.Progress
+	events   <-chan tea.Msg
+}
+
+// StartedMsg is sent after launching with one result per (directory,
+// profile). When DryRun is set nothing was started and Commands holds what
+// would have run.
+type StartedMsg struct {
+	Results  []launcher.Result
+	DryRun   bool
+	Commands []launcher.Command
+}
+
+// SessionsMsg carries the recorded sessions, reloaded so the list shows
+// which are still running.
+type SessionsMsg struct {
+	Sessions []launcher.Session
+	watch    bool // sent by WatchSessions, which schedules the next reload
+}
+
+// StoppedMsg is sent after stopping Count running sessions.
+type StoppedMsg struct {
+	Count int
+	Err   error
+}
+
+const (
+	sessionRefresh = 2 * time.Second // how often WatchSessions reloads
+	stopTimeout    = 5 * time.Second // how long stopped sessions get before SIGKILL
+)
+
+// WatchSessions reloads the sessions every sessionRefresh for as long as
+// the program runs. The app starts it once.
+func WatchSessions() tea.Cmd {
+	return tea.Tick(sessionRefresh, func(time.Time) tea.Msg { return loadSessions(true) })
+}
+
+func loadSessions(watch bool) tea.Msg {
+	sessions, _ := launcher.Sessions()
+	return SessionsMsg{Sessions: sessions, watch: watch}
+}
 
 // reservedLines is the number of lines used by the header, footer, and margins.
 const reservedLines = 5
@@ -40,12 +104,32 @@ type Model struct {
 	cursor   int
 	mode     screenMode
 	// assign mode state
-	assignDirIdx  int
-	assignCursor  int
-	assignToggled map[string]bool
+	assignDirIdx   int
+	assignCursor   int
+	assignToggled  map[string]bool
+	assignTerm     string // terminal override for the directory, "" to inherit
+	assignEnv      map[string]string
+	assignActivate string   // one of config.Activations
+	assignWrapper  []string // argv for config.ActivateWrapper
+	editing        assignField
+	assignInput    textinput.Model // edits the field named by editing
+	inputErr       string
+	// pick mode state
+	pickCursor int
+	// stop mode state
+	stopChoices []launcher.Session
+	stopCursor  int
+	// sessions are the recorded launches, for the running indicators.
+	sessions []launcher.Session
+	// confirmStopAll is set by a first press of StopAll.
+	confirmStopAll bool
 	// change src mode state
 	srcInput  textinput.Model
 	statusMsg string
+	// dryRun makes Start print the resolved commands instead of running them.
+	dryRun bool
+	// cancelLaunch is non-nil while a launch is running in the background.
+	cancelLaunch context.CancelFunc
 	// scroll state
 	height       int
 	scrollOffset int
@@ -56,21 +140,71 @@ func New(cfg *config.Config, l launcher.Launcher) Model {
 	ti.Placeholder = "/home/user/src"
 	ti.CharLimit = 256
 	ti.Width = 50
+	in := textinput.New()
+	in.CharLimit = 512
+	in.Width = 50
+	sessions, _ := launcher.Sessions()
 	return Model{
-		cfg:      cfg,
-		launcher: l,
-		srcInput: ti,
-		height:   24,
+		cfg:         cfg,
+		launcher:    l,
+		srcInput:    ti,
+		assignInput: in,
+		sessions:    sessions,
+		height:      24,
 	}
 }
 
 func (m Model) Init() tea.Cmd { return nil }
 
+// Refresh returns m back in its list after another screen changed the
+// configuration, keeping a running launch and the dry-run setting.
+func (m Model) Refresh() Model {
+	m.mode = modeList
+	if m.cursor >= len(m.cfg.Directories) {
+		m.cursor = max(len(m.cfg.Directories)-1, 0)
+	}
+	m.clampScroll()
+	return m
+}
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	if wm, ok := msg.(tea.WindowSizeMsg); ok {
 		m.height = wm.Height
 		m.clampScroll()
 	}
+	// Launch messages arrive regardless of which overlay is open.
+	switch msg := msg.(type) {
+	case LaunchMsg:
+		return m.startLaunch(msg)
+	case ProgressMsg:
+		m.statusMsg = "launching " + msg.Progress.String()
+		return m, waitForLaunch(msg.events)
+	case StartedMsg:
+		m.cancelLaunch = nil
+		m.statusMsg = launcher.Summarize(msg.Results)
+		if msg.DryRun {
+			lines := []string{"dry run: " + m.statusMsg}
+			for _, c := range msg.Commands {
+				lines = append(lines, fmt.Sprintf("  %s → %s: %s", c.Dir.Name, c.Profile.Label, launcher.JoinArgv(c.Argv)))
+			}
+			m.statusMsg = strings.Join(lines, "\n")
+		}
+		return m, func() tea.Msg { return loadSessions(false) }
+	case SessionsMsg:
+		m.sessions = msg.Sessions
+		if msg.watch {
+			return m, WatchSessions()
+		}
+		return m, nil
+	case StoppedMsg:
+		switch {
+		case msg.Err != nil:
+			m.statusMsg = "stop: " + msg.Err.Error()
+		case msg.Count > 0:
+			m.statusMsg = fmt.Sprintf("stopped %d %s", msg.Count, plural(msg.Count, "session"))
+		}
+		return m, func() tea.Msg { return loadSessions(false) }
+	}
 	switch m.mode {
 	case modeList:
 		return m.updateList(msg)
@@ -78,6 +212,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.updateAssign(msg)
 	case modeChangeSrc:
 		return m.updateChangeSrc(msg)
+	case modePick:
+		return m.updatePick(msg)
+	case modeStop:
+		return m.updateStop(msg)
 	}
 	return m, nil
 }
@@ -85,6 +223,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 func (m Model) updateList(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		confirmStopAll := m.confirmStopAll
+		m.confirmStopAll = false
 		switch {
 		case key.Matches(msg, keys.Main.Quit):
 			return m, tea.Quit
@@ -140,24 +280,248 @@ func (m Model) updateList(msg tea.Msg) (Model, tea.Cmd) {
 				m.clampScroll()
 			}
 		case key.Matches(msg, keys.Main.Start):
-			err := m.launcher.Launch(m.cfg.Directories, m.cfg.Profiles, m.cfg.Terminal)
-			return m, func() tea.Msg { return StartedMsg{Err: err} }
+			if m.cancelLaunch != nil {
+				return m, nil
+			}
+			dryRun := m.dryRun
+			return m, func() tea.Msg { return GoPlanMsg{DryRun: dryRun} }
+		case key.Matches(msg, keys.Main.Open):
+			if len(m.cfg.Directories) == 0 || m.cancelLaunch != nil {
+				return m, nil
+			}
+			d := m.cfg.Directories[m.cursor]
+			if len(d.ProfileIDs) == 0 {
+				m.statusMsg = d.Name + " has no profiles — press enter to assign some"
+				return m, nil
+			}
+			return m.launchDir(d, d.ProfileIDs)
+		case key.Matches(msg, keys.Main.OpenOne):
+			if len(m.cfg.Directories) == 0 || m.cancelLaunch != nil {
+				return m, nil
+			}
+			if len(m.cfg.Profiles) == 0 {
+				m.statusMsg = "no profiles — press p to add one"
+				return m, nil
+			}
+			m.enterPick()
+		case key.Matches(msg, keys.Main.Stop):
+			if len(m.cfg.Directories) == 0 {
+				return m, nil
+			}
+			d := m.cfg.Directories[m.cursor]
+			live := running(m.dirSessions(d.Path, ""))
+			switch len(live) {
+			case 0:
+				m.statusMsg = d.Name + " has nothing running"
+			case 1:
+				return m, m.stop(live)
+			default:
+				m.mode = modeStop
+				m.stopChoices = live
+				m.stopCursor = 0
+			}
+		case key.Matches(msg, keys.Main.StopAll):
+			n := len(running(m.sessions))
+			switch {
+			case n == 0:
+				// Nothing to signal, but exited sessions are forgotten.
+				m.statusMsg = "nothing running"
+				if len(m.sessions) > 0 {
+					m.statusMsg = "nothing running; cleared exited sessions"
+					return m, m.stop(m.sessions)
+				}
+			case !confirmStopAll:
+				m.confirmStopAll = true
+				m.statusMsg = fmt.Sprintf("press X again to stop %d running %s", n, plural(n, "session"))
+			default:
+				return m, m.stop(m.sessions)
+			}
+		case key.Matches(msg, keys.Main.Cancel):
+			if m.cancelLaunch != nil {
+				m.cancelLaunch()
+				m.statusMsg = "cancelling…"
+			}
+		case key.Matches(msg, keys.Main.DryRun):
+			m.dryRun = !m.dryRun
+			if m.dryRun {
+				m.statusMsg = "dry run on: s shows commands without launching"
+			} else {
+				m.statusMsg = "dry run off"
+			}
 		case key.Matches(msg, keys.Main.ChangeSrc):
 			m.srcInput.SetValue(m.cfg.SrcDir)
 			m.srcInput.Focus()
 			m.mode = modeChangeSrc
 			return m, textinput.Blink
 		}
-	case StartedMsg:
-		if msg.Err != nil {
-			m.statusMsg = fmt.Sprintf("error: %v", msg.Err)
-		} else {
-			m.statusMsg = "launched!"
+	}
+	return m, nil
+}
+
+// launchDir launches the profiles ids in d alone, whether or not d is
+// enabled. The launch gets a copy, so no Enabled flag changes.
+func (m Model) launchDir(d config.DirConfig, ids []string) (Model, tea.Cmd) {
+	d.Enabled = true
+	d.ProfileIDs = append([]string(nil), ids...)
+	return m.startLaunch(LaunchMsg{Dirs: []config.DirConfig{d}, DryRun: m.dryRun})
+}
+
+// enterPick opens the one-profile menu for the cursor's directory, on the
+// first profile assigned to it.
+func (m *Model) enterPick() {
+	m.mode = modePick
+	m.pickCursor = 0
+	assigned := m.cfg.Directories[m.cursor].ProfileIDs
+	for i, p := range m.cfg.Profiles {
+		if slices.Contains(assigned, p.ID) {
+			m.pickCursor = i
+			break
+		}
+	}
+}
+
+func (m Model) updatePick(msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Pick.Back):
+			m.mode = modeList
+		case key.Matches(msg, keys.Pick.Up):
+			if m.pickCursor > 0 {
+				m.pickCursor--
+			}
+		case key.Matches(msg, keys.Pick.Down):
+			if m.pickCursor < len(m.cfg.Profiles)-1 {
+				m.pickCursor++
+			}
+		case key.Matches(msg, keys.Pick.Confirm):
+			m.mode = modeList
+			return m.launchDir(m.cfg.Directories[m.cursor], []string{m.cfg.Profiles[m.pickCursor].ID})
 		}
 	}
 	return m, nil
 }
 
+// stop stops sessions in the background, reporting with a StoppedMsg.
+func (m *Model) stop(sessions []launcher.Session) tea.Cmd {
+	n := len(running(sessions))
+	if n > 0 {
+		m.statusMsg = "stopping…"
+	}
+	return func() tea.Msg {
+		return StoppedMsg{Count: n, Err: launcher.Stop(sessions, stopTimeout)}
+	}
+}
+
+func (m Model) updateStop(msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Pick.Back):
+			m.mode = modeList
+		case key.Matches(msg, keys.Pick.Up):
+			if m.stopCursor > 0 {
+				m.stopCursor--
+			}
+		case key.Matches(msg, keys.Pick.Down):
+			if m.stopCursor < len(m.stopChoices)-1 {
+				m.stopCursor++
+			}
+		case key.Matches(msg, keys.Pick.Confirm):
+			m.mode = modeList
+			return m, m.stop(m.stopChoices[m.stopCursor : m.stopCursor+1])
+		}
+	}
+	return m, nil
+}
+
+// dirSessions returns the sessions launched in the directory at path, only
+// those of profileID unless it is empty.
+func (m Model) dirSessions(path, profileID string) []launcher.Session {
+	var out []launcher.Session
+	for _, s := range m.sessions {
+		if s.Dir == path && (profileID == "" || s.ProfileID == profileID) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func running(sessions []launcher.Session) []launcher.Session {
+	var out []launcher.Session
+	for _, s := range sessions {
+		if s.Running {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// sessionMark is a green dot when one of sessions is running, a hollow one
+// when they have all exited, and nothing when there are none.
+func sessionMark(sessions []launcher.Session) string {
+	switch {
+	case len(running(sessions)) > 0:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("●")
+	case len(sessions) > 0:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("○")
+	}
+	return ""
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// startLaunch runs the launcher in a goroutine and streams its progress back
+// as messages, so slow terminals never block the UI.
+func (m Model) startLaunch(msg LaunchMsg) (Model, tea.Cmd) {
+	if m.cancelLaunch != nil {
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelLaunch = cancel
+	m.statusMsg = "launching…"
+
+	var l launcher.Launcher = m.launcher
+	var dr *launcher.DryRun
+	if msg.DryRun {
+		dr = &launcher.DryRun{}
+		l = dr
+	}
+	// The launch runs while other screens edit the configuration, so it
+	// works on a copy, directories included.
+	snapshot := m.cfg.Clone()
+	snapshot.Directories = msg.Dirs
+	snapshot = snapshot.Clone()
+	req := launcher.Request{Config: snapshot, Dirs: snapshot.Directories}
+
+	events := make(chan tea.Msg)
+	req.Progress = func(p launcher.Progress) {
+		select {
+		case events <- ProgressMsg{Progress: p, events: events}:
+		case <-ctx.Done():
+		}
+	}
+	go func() {
+		defer cancel()
+		results := l.Launch(ctx, req)
+		done := StartedMsg{Results: results, DryRun: msg.DryRun}
+		if dr != nil {
+			done.Commands = dr.Commands
+		}
+		events <- done
+		close(events)
+	}()
+	return m, waitForLaunch(events)
+}
+
+// waitForLaunch delivers the next message from a running launch.
+func waitForLaunch(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-events }
+}
+
 // visibleRows returns the number of directory rows that can be shown on screen.
 func (m Model) visibleRows() int {
 	rows := m.height - reservedLines
@@ -198,9 +562,51 @@ func (m *Model) enterAssign(dirIdx int) {
 		selected[pid] = true
 	}
 	m.assignToggled = selected
+	m.assignTerm = m.cfg.Directories[dirIdx].Terminal
+	m.assignEnv = m.cfg.Directories[dirIdx].Env
+	m.assignActivate = m.cfg.Directories[dirIdx].Activate
+	m.assignWrapper = m.cfg.Directories[dirIdx].Wrapper
+	m.editing = editNone
+}
+
+// nextActivation returns the activation after a in config.Activations,
+// wrapping around.
+func nextActivation(a string) string {
+	for i, c := range config.Activations {
+		if c == a {
+			return config.Activations[(i+1)%len(config.Activations)]
+		}
+	}
+	return config.ActivateNone
+}
+
+// edit focuses the overlay's input on field, starting from value.
+func (m *Model) edit(field assignField, value, placeholder string) tea.Cmd {
+	m.editing = field
+	m.inputErr = ""
+	m.assignInput.Placeholder = placeholder
+	m.assignInput.SetValue(value)
+	m.assignInput.CursorEnd()
+	m.assignInput.Focus()
+	return textinput.Blink
+}
+
+// nextTerminal returns the override after term in cfg.TerminalOverrides,
+// wrapping around to inheriting.
+func (m Model) nextTerminal(term string) string {
+	choices := m.cfg.TerminalOverrides()
+	for i, c := range choices {
+		if c == term {
+			return choices[(i+1)%len(choices)]
+		}
+	}
+	return ""
 }
 
 func (m Model) updateAssign(msg tea.Msg) (Model, tea.Cmd) {
+	if m.editing != editNone {
+		return m.updateAssignInput(msg)
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
@@ -219,6 +625,17 @@ func (m Model) updateAssign(msg tea.Msg) (Model, tea.Cmd) {
 				pid := m.cfg.Profiles[m.assignCursor].ID
 				m.assignToggled[pid] = !m.assignToggled[pid]
 			}
+		case key.Matches(msg, keys.Assign.Terminal):
+			m.assignTerm = m.nextTerminal(m.assignTerm)
+		case key.Matches(msg, keys.Assign.Env):
+			return m, m.edit(editEnv, config.FormatEnv(m.assignEnv), "NAME=value ...")
+		case key.Matches(msg, keys.Assign.Activate):
+			next := nextActivation(m.assignActivate)
+			if next == config.ActivateWrapper {
+				// A wrapper needs its command before it can be chosen.
+				return m, m.edit(editWrapper, launcher.JoinArgv(m.assignWrapper), "e.g. devbox run --")
+			}
+			m.assignActivate = next
 		case key.Matches(msg, keys.Assign.Confirm):
 			// Save selections back.
 			var ids []string
@@ -228,6 +645,10 @@ func (m Model) updateAssign(msg tea.Msg) (Model, tea.Cmd) {
 				}
 			}
 			m.cfg.Directories[m.assignDirIdx].ProfileIDs = ids
+			m.cfg.Directories[m.assignDirIdx].Terminal = m.assignTerm
+			m.cfg.Directories[m.assignDirIdx].Env = m.assignEnv
+			m.cfg.Directories[m.assignDirIdx].Activate = m.assignActivate
+			m.cfg.Directories[m.assignDirIdx].Wrapper = m.assignWrapper
 			_ = m.cfg.Save()
 			m.mode = modeList
 		}
@@ -235,6 +656,52 @@ func (m Model) updateAssign(msg tea.Msg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateAssignInput edits the directory's environment or activation
+// wrapper in the assign overlay. Either is only saved with the rest of the
+// overlay. Leaving the wrapper empty, or pressing esc, moves the
+// activation past the wrapper to none.
+func (m Model) updateAssignInput(msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			if m.editing == editWrapper {
+				m.assignActivate = config.ActivateNone
+			}
+			m.editing = editNone
+			m.assignInput.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			val := strings.TrimSpace(m.assignInput.Value())
+			switch m.editing {
+			case editEnv:
+				env, err := config.ParseEnv(val)
+				if err != nil {
+					m.inputErr = err.Error()
+					return m, nil
+				}
+				m.assignEnv = env
+			case editWrapper:
+				wrap, err := config.ParseArgv(val)
+				if err != nil {
+					m.inputErr = err.Error()
+					return m, nil
+				}
+				m.assignActivate = config.ActivateNone
+				if len(wrap) > 0 {
+					m.assignActivate = config.ActivateWrapper
+					m.assignWrapper = wrap
+				}
+			}
+			m.editing = editNone
+			m.assignInput.Blur()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.assignInput, cmd = m.assignInput.Update(msg)
+	return m, cmd
+}
+
 func (m Model) updateChangeSrc(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -273,6 +740,10 @@ func (m Model) View() string {
 		return m.viewAssign()
 	case modeChangeSrc:
 		return m.viewChangeSrc()
+	case modePick:
+		return m.viewPick()
+	case modeStop:
+		return m.viewStop()
 	default:
 		return m.viewList()
 	}
@@ -281,6 +752,9 @@ func (m Model) View() string {
 func (m Model) viewList() string {
 	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("gopener")
 	srcLine := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("src: " + m.cfg.SrcDir)
+	if m.dryRun {
+		srcLine += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Render("[dry run]")
+	}
 
 	var sb strings.Builder
 	sb.WriteString(title + "  " + srcLine + "\n\n")
@@ -303,16 +777,37 @@ func (m Model) viewList() string {
 			check = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("[x]")
 		}
 
-		// Collect profile labels for this dir.
+		// Collect profile labels for this dir, marking the launched ones,
+		// including any launched without being assigned.
+		dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+		ids := append([]string(nil), d.ProfileIDs...)
+		for _, s := range m.dirSessions(d.Path, "") {
+			if !slices.Contains(ids, s.ProfileID) {
+				ids = append(ids, s.ProfileID)
+			}
+		}
 		var labels []string
-		for _, pid := range d.ProfileIDs {
+		for _, pid := range ids {
 			if p := m.cfg.FindProfile(pid); p != nil {
-				labels = append(labels, p.Label)
+				label := dim.Render(p.Label)
+				if mark := sessionMark(m.dirSessions(d.Path, pid)); mark != "" {
+					label += " " + mark
+				}
+				labels = append(labels, label)
 			}
 		}
 		profilesStr := ""
 		if len(labels) > 0 {
-			profilesStr = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(" [" + strings.Join(labels, ", ") + "]")
+			profilesStr = dim.Render(" [") + strings.Join(labels, dim.Render(", ")) + dim.Render("]")
+		}
+		if d.Terminal != "" {
+			profilesStr += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" (in " + d.Terminal + ")")
+		}
+		if d.Activate != config.ActivateNone {
+			profilesStr += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" (" + activationDesc(d.Activate, d.Wrapper) + ")")
+		}
+		if len(d.Env) > 0 {
+			profilesStr += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" (env: " + strings.Join(config.EnvNames(d.Env), ", ") + ")")
 		}
 
 		cursor := "  "
@@ -321,6 +816,9 @@ func (m Model) viewList() string {
 			cursor = "▸ "
 			nameStr = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Render(d.Name)
 		}
+		if mark := sessionMark(m.dirSessions(d.Path, "")); mark != "" {
+			nameStr += " " + mark
+		}
 		line := fmt.Sprintf("%s%s %s%s", cursor, check, nameStr, profilesStr)
 		sb.WriteString(line + "\n")
 	}
@@ -330,8 +828,11 @@ func (m Model) viewList() string {
 	}
 
 	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(
-		"\n  space toggle  enter assign  p profiles  t settings  s start  r rescan  c change src  q quit",
+		"\n  space toggle  enter assign  p profiles  t settings  s start  o open  O open one  x stop  X stop all  d dry run  r rescan  c change src  q quit",
 	)
+	if m.cancelLaunch != nil {
+		help = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("\n  esc cancel launch  q quit")
+	}
 	sb.WriteString(help)
 	return sb.String()
 }
@@ -342,6 +843,54 @@ func (m Model) viewChangeSrc() string {
 	return strings.Join([]string{title, "", "  " + m.srcInput.View(), "", help}, "\n")
 }
 
+func (m Model) viewPick() string {
+	dir := m.cfg.Directories[m.cursor]
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Open one profile → " + dir.Name)
+	var sb strings.Builder
+	sb.WriteString(title + "\n\n")
+	for i, p := range m.cfg.Profiles {
+		cursor := "  "
+		label := p.Label
+		if i == m.pickCursor {
+			cursor = "▸ "
+			label = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Render(p.Label)
+		}
+		line := cursor + label
+		if slices.Contains(dir.ProfileIDs, p.ID) {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (assigned)")
+		}
+		sb.WriteString(line + "\n")
+	}
+	help := "\n  enter open  esc cancel"
+	if m.dryRun {
+		help = "\n  enter dry run  esc cancel"
+	}
+	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(help))
+	return sb.String()
+}
+
+func (m Model) viewStop() string {
+	dir := m.cfg.Directories[m.cursor]
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Stop a session → " + dir.Name)
+	var sb strings.Builder
+	sb.WriteString(title + "\n\n")
+	for i, s := range m.stopChoices {
+		cursor := "  "
+		label := s.ProfileID
+		if p := m.cfg.FindProfile(s.ProfileID); p != nil {
+			label = p.Label
+		}
+		if i == m.stopCursor {
+			cursor = "▸ "
+			label = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Render(label)
+		}
+		detail := fmt.Sprintf("  pid %d, started %s", s.PID, s.Started.Format("Jan 2 15:04"))
+		sb.WriteString(cursor + label + lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(detail) + "\n")
+	}
+	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("\n  enter stop  esc cancel"))
+	return sb.String()
+}
+
 func (m Model) viewAssign() string {
 	if m.assignDirIdx >= len(m.cfg.Directories) {
 		return ""
@@ -351,8 +900,33 @@ func (m Model) viewAssign() string {
 		fmt.Sprintf("Assign profiles → %s", dir.Name),
 	)
 
+	term := "each profile's terminal"
+	if m.assignTerm != "" {
+		term = m.assignTerm + " for every profile"
+	}
 	var sb strings.Builder
-	sb.WriteString(title + "\n\n")
+	sb.WriteString(title + "\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  terminal: "+term) + "\n")
+	input := func(label string) {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Render("  "+label+": ") + m.assignInput.View() + "\n")
+		if m.inputErr != "" {
+			sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("  "+m.inputErr) + "\n")
+		}
+	}
+	switch {
+	case m.editing == editEnv:
+		input("env")
+	case len(m.assignEnv) > 0:
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  env: "+config.FormatEnv(m.assignEnv)+" (over each profile's)") + "\n")
+	default:
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  env: each profile's") + "\n")
+	}
+	if m.editing == editWrapper {
+		input("activate through")
+	} else {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  activate: "+activationDesc(m.assignActivate, m.assignWrapper)) + "\n")
+	}
+	sb.WriteString("\n")
 
 	if len(m.cfg.Profiles) == 0 {
 		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (no profiles — press esc, then p to add)") + "\n")
@@ -373,8 +947,30 @@ func (m Model) viewAssign() string {
 	}
 
 	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(
-		"\n  space toggle  enter confirm  esc cancel",
+		"\n  space toggle  t terminal  v environment  a activation  enter confirm  esc cancel",
 	)
+	switch m.editing {
+	case editEnv:
+		help = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("\n  enter set environment  esc cancel")
+	case editWrapper:
+		help = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("\n  enter set wrapper ({dir} is the directory)  esc skip")
+	}
 	sb.WriteString(help)
 	return sb.String()
 }
+
+// activationDesc describes a directory's environment activation in the
+// assign overlay.
+func activationDesc(activate string, wrapper []string) string {
+	switch activate {
+	case config.ActivateDotenv:
+		return "load .env"
+	case config.ActivateDirenv:
+		return "direnv exec"
+	case config.ActivateNix:
+		return "nix develop"
+	case config.ActivateWrapper:
+		return launcher.JoinArgv(wrapper)
+	}
+	return "none"
+}
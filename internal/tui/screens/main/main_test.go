@@ -1,30 +1,67 @@
 package mainscreen
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/launcher"
 )
 
 func TestMain(m *testing.M) {
 	dir, _ := os.MkdirTemp("", "gopener-main-test-*")
 	defer os.RemoveAll(dir)
 	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("XDG_STATE_HOME", dir)
 	os.Exit(m.Run())
 }
 
 // noopLauncher satisfies launcher.Launcher for tests.
 type noopLauncher struct{ called bool }
 
-func (n *noopLauncher) Launch(dirs []config.DirConfig, profiles []config.Profile, terminal string) error {
+func (n *noopLauncher) Launch(ctx context.Context, req launcher.Request) []launcher.Result {
 	n.called = true
 	return nil
 }
 
+// recordingLauncher records the configuration and directories of each
+// launch.
+type recordingLauncher struct {
+	cfgs []*config.Config
+	dirs [][]config.DirConfig
+}
+
+func (r *recordingLauncher) Launch(ctx context.Context, req launcher.Request) []launcher.Result {
+	r.cfgs = append(r.cfgs, req.Config)
+	r.dirs = append(r.dirs, req.Dirs)
+	return nil
+}
+
+// progressLauncher reports progress for every enabled directory and blocks
+// after the first item until ctx is cancelled.
+type progressLauncher struct{}
+
+func (progressLauncher) Launch(ctx context.Context, req launcher.Request) []launcher.Result {
+	var results []launcher.Result
+	for i, d := range req.Dirs {
+		if ctx.Err() != nil {
+			results = append(results, launcher.Result{Dir: d, Status: launcher.StatusSkipped, Reason: "cancelled"})
+			continue
+		}
+		req.Progress(launcher.Progress{Index: i + 1, Total: len(req.Dirs), Command: launcher.Command{Dir: d, Profile: req.Config.Profiles[0]}})
+		results = append(results, launcher.Result{Dir: d, Status: launcher.StatusLaunched})
+		<-ctx.Done()
+	}
+	return results
+}
+
 func makeCfg() *config.Config {
 	return &config.Config{
 		SrcDir: "/tmp/src",
@@ -125,16 +162,22 @@ func TestGoProfilesMsg(t *testing.T) {
 	}
 }
 
-func TestStartLaunches(t *testing.T) {
+func TestStartOpensPlan(t *testing.T) {
 	l := &noopLauncher{}
 	m := New(makeCfg(), l)
 	_, cmd := pressRune(m, 's')
 	if cmd == nil {
 		t.Fatal("expected cmd after 's'")
 	}
-	cmd() // execute — calls launcher
-	if !l.called {
-		t.Error("launcher was not called")
+	msg, ok := cmd().(GoPlanMsg)
+	if !ok {
+		t.Fatalf("expected GoPlanMsg, got %T", msg)
+	}
+	if msg.DryRun {
+		t.Error("dry run should be off by default")
+	}
+	if l.called {
+		t.Error("launcher should not be called before the plan is confirmed")
 	}
 }
 
@@ -161,6 +204,123 @@ func TestAssignEsc(t *testing.T) {
 	}
 }
 
+func TestAssignTerminal(t *testing.T) {
+	cfg := makeCfg()
+	cfg.Terminals = []config.CustomTerminal{{Name: "foot", Binary: "foot", Argv: []string{"-e", "{cmd}"}}}
+	m := New(cfg, &noopLauncher{})
+	m.enterAssign(1)
+
+	// Cycle through the choices until the custom terminal comes up.
+	for i := 0; m.assignTerm != "foot"; i++ {
+		if i > len(cfg.TerminalOverrides()) {
+			t.Fatal("foot never offered")
+		}
+		m, _ = pressRune(m, 't')
+	}
+	if v := m.View(); !strings.Contains(v, "terminal: foot") {
+		t.Errorf("overlay should show the terminal:\n%s", v)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if cfg.Directories[1].Terminal != "foot" {
+		t.Errorf("saved terminal: got %q", cfg.Directories[1].Terminal)
+	}
+
+	// Wrapping around goes back to inheriting.
+	m.enterAssign(1)
+	m, _ = pressRune(m, 't')
+	if m.assignTerm != "" {
+		t.Errorf("after foot: got %q, want inherit", m.assignTerm)
+	}
+}
+
+func TestAssignEnv(t *testing.T) {
+	cfg := makeCfg()
+	m := New(cfg, &noopLauncher{})
+	m.enterAssign(1)
+
+	m, _ = pressRune(m, 'v')
+	if m.editing != editEnv {
+		t.Fatal("v should start editing the environment")
+	}
+	// Keys go to the input while editing, not to the overlay.
+	for _, r := range "AWS_PROFILE=work t" {
+		m, _ = pressRune(m, r)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.editing != editEnv || m.inputErr == "" {
+		t.Fatalf("expected an error for a pair without =, got editing %v err %q", m.editing, m.inputErr)
+	}
+	m.assignInput.SetValue("AWS_PROFILE=work")
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.editing != editNone || m.assignTerm != "" {
+		t.Fatalf("after enter: editing %v, terminal %q", m.editing, m.assignTerm)
+	}
+	if v := m.View(); !strings.Contains(v, "env: AWS_PROFILE=work") {
+		t.Errorf("overlay should show the environment:\n%s", v)
+	}
+	if cfg.Directories[1].Env != nil {
+		t.Error("env saved before the overlay was confirmed")
+	}
+
+	m, _ = pressKey(m, tea.KeyEnter)
+	if got := cfg.Directories[1].Env["AWS_PROFILE"]; got != "work" {
+		t.Errorf("saved env: got %v", cfg.Directories[1].Env)
+	}
+	if v := m.View(); !strings.Contains(v, "(env: AWS_PROFILE)") {
+		t.Errorf("list should name the variables:\n%s", v)
+	}
+}
+
+func TestAssignActivation(t *testing.T) {
+	cfg := makeCfg()
+	m := New(cfg, &noopLauncher{})
+	m.enterAssign(1)
+
+	for _, want := range []string{config.ActivateDotenv, config.ActivateDirenv, config.ActivateNix} {
+		m, _ = pressRune(m, 'a')
+		if m.assignActivate != want {
+			t.Fatalf("got %q, want %q", m.assignActivate, want)
+		}
+	}
+
+	// The wrapper asks for its command first.
+	m, _ = pressRune(m, 'a')
+	if m.editing != editWrapper || m.assignActivate != config.ActivateNix {
+		t.Fatalf("wrapper: editing %v, activation %q", m.editing, m.assignActivate)
+	}
+	// It is split like a shell would, and input that does not parse is
+	// rejected.
+	for _, r := range "nix develop -c sh -c 'a b" {
+		m, _ = pressRune(m, r)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.editing != editWrapper || m.inputErr == "" {
+		t.Fatalf("unclosed quote: editing %v, error %q", m.editing, m.inputErr)
+	}
+	m, _ = pressRune(m, '\'')
+	m, _ = pressKey(m, tea.KeyEnter)
+	want := []string{"nix", "develop", "-c", "sh", "-c", "a b"}
+	if m.assignActivate != config.ActivateWrapper || !reflect.DeepEqual(m.assignWrapper, want) {
+		t.Fatalf("after enter: activation %q, wrapper %q", m.assignActivate, m.assignWrapper)
+	}
+	if v := m.View(); !strings.Contains(v, "activate: nix develop -c sh -c 'a b'") {
+		t.Errorf("overlay should show the wrapper:\n%s", v)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if d := cfg.Directories[1]; d.Activate != config.ActivateWrapper || !reflect.DeepEqual(d.Wrapper, want) {
+		t.Errorf("saved: activation %q, wrapper %q", d.Activate, d.Wrapper)
+	}
+
+	// Skipping the wrapper wraps around to none.
+	m.enterAssign(1)
+	m.assignActivate = config.ActivateNix
+	m, _ = pressRune(m, 'a')
+	m, _ = pressKey(m, tea.KeyEsc)
+	if m.mode != modeAssign || m.assignActivate != config.ActivateNone {
+		t.Errorf("after esc: mode %v, activation %q", m.mode, m.assignActivate)
+	}
+}
+
 func TestChangeSrcEnterMode(t *testing.T) {
 	m := New(makeCfg(), &noopLauncher{})
 
@@ -368,3 +528,286 @@ func TestPageUpClampsAtTop(t *testing.T) {
 		t.Errorf("after pgup at top: scrollOffset=%d, want 0", m.scrollOffset)
 	}
 }
+
+func TestDryRunToggle(t *testing.T) {
+	m := New(makeCfg(), &noopLauncher{})
+
+	m, _ = pressRune(m, 'd')
+	if !m.dryRun {
+		t.Fatal("expected dry run to be enabled after 'd'")
+	}
+	_, cmd := pressRune(m, 's')
+	if msg, ok := cmd().(GoPlanMsg); !ok || !msg.DryRun {
+		t.Errorf("expected GoPlanMsg{DryRun: true}, got %#v", msg)
+	}
+
+	m, _ = pressRune(m, 'd')
+	if m.dryRun {
+		t.Error("expected dry run to be disabled after second 'd'")
+	}
+}
+
+func TestStartedMsgDryRunStatus(t *testing.T) {
+	m := New(makeCfg(), &noopLauncher{})
+	cmds := []launcher.Command{{
+		Dir:     config.DirConfig{Name: "beta"},
+		Profile: config.Profile{Label: "Claude"},
+		Argv:    []string{"xterm", "-e", "claude"},
+	}}
+	results := []launcher.Result{{Dir: cmds[0].Dir, Profile: cmds[0].Profile, Status: launcher.StatusPlanned}}
+	m, _ = m.Update(StartedMsg{Results: results, DryRun: true, Commands: cmds})
+	if !strings.Contains(m.statusMsg, "dry run: 1 planned") || !strings.Contains(m.statusMsg, "beta → Claude: xterm -e claude") {
+		t.Errorf("statusMsg: got %q", m.statusMsg)
+	}
+}
+
+func TestStartedMsgSummary(t *testing.T) {
+	m := New(makeCfg(), &noopLauncher{})
+	dir := config.DirConfig{Name: "api-server"}
+	results := []launcher.Result{
+		{Dir: dir, Status: launcher.StatusLaunched},
+		{Dir: dir, Status: launcher.StatusLaunched},
+		{Dir: dir, Status: launcher.StatusFailed, Err: errors.New("kitty not found")},
+	}
+	m, _ = m.Update(StartedMsg{Results: results})
+	if want := "2 launched, 1 failed: kitty not found for api-server"; m.statusMsg != want {
+		t.Errorf("statusMsg: got %q, want %q", m.statusMsg, want)
+	}
+}
+
+func TestLaunchStreamsProgressAndCancels(t *testing.T) {
+	c := makeCfg()
+	m := New(c, progressLauncher{})
+
+	m, cmd := m.Update(LaunchMsg{Dirs: c.Directories})
+	if m.cancelLaunch == nil {
+		t.Fatal("expected a running launch")
+	}
+
+	// First message is progress for item 1.
+	msg := cmd()
+	progress, ok := msg.(ProgressMsg)
+	if !ok {
+		t.Fatalf("expected ProgressMsg, got %T", msg)
+	}
+	m, cmd = m.Update(progress)
+	if m.statusMsg != "launching 1/2: alpha → Claude" {
+		t.Errorf("statusMsg: got %q", m.statusMsg)
+	}
+
+	// Starting again while running is ignored.
+	if _, again := pressRune(m, 's'); again != nil {
+		t.Error("start should be ignored while launching")
+	}
+
+	// Esc cancels; the launcher then finishes with the rest skipped.
+	m, _ = pressKey(m, tea.KeyEsc)
+	msg = cmd()
+	started, ok := msg.(StartedMsg)
+	if !ok {
+		t.Fatalf("expected StartedMsg, got %T", msg)
+	}
+	m, _ = m.Update(started)
+	if m.cancelLaunch != nil {
+		t.Error("launch should be finished")
+	}
+	if m.statusMsg != "1 launched, 1 skipped" {
+		t.Errorf("statusMsg: got %q", m.statusMsg)
+	}
+}
+
+func TestOpenDir(t *testing.T) {
+	c := makeCfg()
+	rec := &recordingLauncher{}
+	m := New(c, rec)
+
+	// alpha has no profiles, so there is nothing to open.
+	m, cmd := pressRune(m, 'o')
+	if cmd != nil || !strings.Contains(m.statusMsg, "alpha has no profiles") {
+		t.Fatalf("statusMsg %q, cmd %v", m.statusMsg, cmd)
+	}
+
+	c.Directories[1].Enabled = false
+	m, _ = pressKey(m, tea.KeyDown)
+	m, cmd = pressRune(m, 'o')
+	if cmd == nil || m.cancelLaunch == nil {
+		t.Fatal("expected a launch")
+	}
+	if _, ok := cmd().(StartedMsg); !ok {
+		t.Fatal("expected StartedMsg")
+	}
+	if len(rec.dirs) != 1 || len(rec.dirs[0]) != 1 {
+		t.Fatalf("launched %+v", rec.dirs)
+	}
+	if d := rec.dirs[0][0]; d.Name != "beta" || !d.Enabled || len(d.ProfileIDs) != 1 {
+		t.Errorf("launched %+v", d)
+	}
+	if c.Directories[0].Enabled || c.Directories[1].Enabled {
+		t.Error("opening a directory should not change Enabled")
+	}
+	// The launch runs on a copy, since other screens go on editing c.
+	if rec.cfgs[0] == c || len(rec.cfgs[0].Profiles) != len(c.Profiles) {
+		t.Errorf("launched with %p, the live config is %p", rec.cfgs[0], c)
+	}
+}
+
+func TestRefreshKeepsLaunch(t *testing.T) {
+	c := makeCfg()
+	m := New(c, progressLauncher{})
+	m.dryRun = true
+	m, _ = m.Update(LaunchMsg{Dirs: c.Directories})
+	defer func() { m.cancelLaunch() }()
+
+	// Back from the profiles screen, which removed a directory meanwhile.
+	m.cursor = len(c.Directories) - 1
+	c.Directories = c.Directories[:1]
+	m = m.Refresh()
+	if m.cancelLaunch == nil || !m.dryRun || m.cursor != 0 {
+		t.Fatalf("refresh lost state: launching %v, dry run %v, cursor %d", m.cancelLaunch != nil, m.dryRun, m.cursor)
+	}
+	if _, cmd := m.Update(LaunchMsg{Dirs: c.Directories}); cmd != nil {
+		t.Error("a second launch should not start while one runs")
+	}
+}
+
+func TestOpenOneProfile(t *testing.T) {
+	c := makeCfg()
+	c.Profiles = append(c.Profiles, config.Profile{ID: "p2", Label: "Shell", Cmd: "bash"})
+	c.Directories[1].ProfileIDs = []string{"p2"}
+	rec := &recordingLauncher{}
+	m := New(c, rec)
+	m, _ = pressKey(m, tea.KeyDown)
+
+	m, _ = pressRune(m, 'O')
+	if m.mode != modePick || m.pickCursor != 1 {
+		t.Fatalf("mode %v, pickCursor %d: expected the menu on beta's profile", m.mode, m.pickCursor)
+	}
+	if view := m.View(); !strings.Contains(view, "Open one profile → beta") || !strings.Contains(view, "Shell  (assigned)") {
+		t.Errorf("view:\n%s", view)
+	}
+
+	// Esc leaves without launching.
+	m, _ = pressKey(m, tea.KeyEsc)
+	if m.mode != modeList || len(rec.dirs) != 0 {
+		t.Fatalf("mode %v, launched %+v", m.mode, rec.dirs)
+	}
+
+	// Claude is not assigned to beta but can still be opened there.
+	m, _ = pressRune(m, 'O')
+	m, _ = pressKey(m, tea.KeyUp)
+	m, cmd := pressKey(m, tea.KeyEnter)
+	if m.mode != modeList || cmd == nil {
+		t.Fatal("expected a launch from the menu")
+	}
+	cmd()
+	if len(rec.dirs) != 1 || len(rec.dirs[0]) != 1 || rec.dirs[0][0].Name != "beta" ||
+		strings.Join(rec.dirs[0][0].ProfileIDs, ",") != "p1" {
+		t.Fatalf("launched %+v", rec.dirs)
+	}
+	if got := strings.Join(c.Directories[1].ProfileIDs, ","); got != "p2" || !c.Directories[1].Enabled {
+		t.Errorf("directory changed: %+v", c.Directories[1])
+	}
+}
+
+// fakeSessions are sessions of process groups that cannot exist, so
+// stopping them signals nothing.
+func fakeSessions() []launcher.Session {
+	return []launcher.Session{
+		{Dir: "/tmp/src/beta", ProfileID: "p1", PID: 1 << 30, PGID: 1 << 30, Started: time.Now(), Running: true},
+		{Dir: "/tmp/src/beta", ProfileID: "p2", PID: 1<<30 + 1, PGID: 1<<30 + 1, Started: time.Now(), Running: true},
+		{Dir: "/tmp/src/alpha", ProfileID: "p1", PID: 1<<30 + 2, PGID: 1<<30 + 2, Started: time.Now()},
+	}
+}
+
+func TestSessionIndicators(t *testing.T) {
+	c := makeCfg()
+	c.Profiles = append(c.Profiles, config.Profile{ID: "p2", Label: "Shell", Cmd: "bash"})
+	m := New(c, &noopLauncher{})
+	m, _ = m.Update(SessionsMsg{Sessions: fakeSessions()})
+
+	lines := strings.Split(m.View(), "\n")
+	var alpha, beta string
+	for _, l := range lines {
+		switch {
+		case strings.Contains(l, "alpha"):
+			alpha = l
+		case strings.Contains(l, "beta"):
+			beta = l
+		}
+	}
+	// Shell was opened in beta without being assigned to it.
+	if !strings.Contains(beta, "beta ●") || !strings.Contains(beta, "Claude ●") || !strings.Contains(beta, "Shell ●") {
+		t.Errorf("beta: %q", beta)
+	}
+	if !strings.Contains(alpha, "alpha ○") || !strings.Contains(alpha, "Claude ○") {
+		t.Errorf("alpha: %q", alpha)
+	}
+
+	// A periodic reload schedules the next one; others do not.
+	if _, cmd := m.Update(SessionsMsg{watch: true}); cmd == nil {
+		t.Error("expected the next reload to be scheduled")
+	}
+	if _, cmd := m.Update(SessionsMsg{}); cmd != nil {
+		t.Error("a one-off reload should not schedule another")
+	}
+}
+
+func TestStopSession(t *testing.T) {
+	c := makeCfg()
+	c.Profiles = append(c.Profiles, config.Profile{ID: "p2", Label: "Shell", Cmd: "bash"})
+	m := New(c, &noopLauncher{})
+	m, _ = m.Update(SessionsMsg{Sessions: fakeSessions()})
+
+	m, cmd := pressRune(m, 'x')
+	if cmd != nil || m.statusMsg != "alpha has nothing running" {
+		t.Fatalf("statusMsg %q", m.statusMsg)
+	}
+
+	// beta runs two sessions, so x asks which one.
+	m, _ = pressKey(m, tea.KeyDown)
+	m, _ = pressRune(m, 'x')
+	if m.mode != modeStop || len(m.stopChoices) != 2 {
+		t.Fatalf("mode %v, choices %+v", m.mode, m.stopChoices)
+	}
+	if view := m.View(); !strings.Contains(view, "Stop a session → beta") || !strings.Contains(view, "Shell  pid 1073741825") {
+		t.Errorf("view:\n%s", view)
+	}
+	m, _ = pressKey(m, tea.KeyDown)
+	m, cmd = pressKey(m, tea.KeyEnter)
+	if m.mode != modeList || cmd == nil {
+		t.Fatal("expected a stop")
+	}
+	if msg := cmd().(StoppedMsg); msg.Count != 1 || msg.Err != nil {
+		t.Errorf("got %+v", msg)
+	}
+}
+
+func TestStopAllSessions(t *testing.T) {
+	m := New(makeCfg(), &noopLauncher{})
+	m, _ = m.Update(SessionsMsg{Sessions: fakeSessions()})
+
+	// The first X only asks; anything else in between cancels.
+	m, cmd := pressRune(m, 'X')
+	if cmd != nil || m.statusMsg != "press X again to stop 2 running sessions" {
+		t.Fatalf("statusMsg %q", m.statusMsg)
+	}
+	m, _ = pressKey(m, tea.KeyDown)
+	if m, cmd = pressRune(m, 'X'); cmd != nil {
+		t.Fatal("X after another key should ask again")
+	}
+	m, cmd = pressRune(m, 'X')
+	if cmd == nil || m.statusMsg != "stopping…" {
+		t.Fatalf("expected a stop, statusMsg %q", m.statusMsg)
+	}
+	msg := cmd().(StoppedMsg)
+	if msg.Count != 2 || msg.Err != nil {
+		t.Errorf("got %+v", msg)
+	}
+	m, cmd = m.Update(msg)
+	if m.statusMsg != "stopped 2 sessions" || cmd == nil {
+		t.Errorf("statusMsg %q", m.statusMsg)
+	}
+	if reload := cmd().(SessionsMsg); len(reload.Sessions) != 0 {
+		t.Errorf("stopped sessions should be forgotten, got %+v", reload.Sessions)
+	}
+}
@@ -0,0 +1,200 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jimbo/gopener/internal/config"
+	"github.com/jimbo/gopener/internal/keys"
+	"github.com/jimbo/gopener/internal/launcher"
+)
+
+// BackMsg is sent when the user cancels without launching.
+type BackMsg struct{}
+
+// ConfirmMsg is sent when the user confirms the plan. Dirs is a copy of the
+// configured directories reduced to the ticked rows, ready to be launched.
+type ConfirmMsg struct {
+	Dirs   []config.DirConfig
+	DryRun bool
+}
+
+// builtMsg carries the plan built by Init for the screen with the same id.
+type builtMsg struct {
+	id   int
+	plan launcher.Plan
+}
+
+// screens numbers each Model, so a plan built for a screen the user already
+// left is not shown on the next one.
+var screens int
+
+type Model struct {
+	id     int
+	cfg    *config.Config
+	dryRun bool
+	loaded bool
+	plan   launcher.Plan
+	ticked []bool
+	cursor int
+}
+
+// New returns the plan screen for every enabled directory in cfg; Init
+// builds the plan. When dryRun is set, confirming asks for the commands to
+// be reported instead of started.
+func New(cfg *config.Config, dryRun bool) Model {
+	screens++
+	return Model{id: screens, cfg: cfg, dryRun: dryRun}
+}
+
+// Init builds the plan in the background, since rendering profile commands
+// may run git in every directory. It plans from a copy of cfg, which the
+// screen does not change.
+func (m Model) Init() tea.Cmd {
+	id, cfg := m.id, m.cfg.Clone()
+	return func() tea.Msg {
+		return builtMsg{id: id, plan: launcher.NewPlan(cfg, cfg.Directories)}
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case builtMsg:
+		if msg.id == m.id {
+			m.plan, m.loaded = msg.plan, true
+			m.ticked = make([]bool, len(msg.plan.Commands))
+			for i := range m.ticked {
+				m.ticked[i] = true
+			}
+		}
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Plan.Back):
+			return m, func() tea.Msg { return BackMsg{} }
+		case !m.loaded:
+		case key.Matches(msg, keys.Plan.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Plan.Down):
+			if m.cursor < len(m.plan.Commands)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, keys.Plan.Toggle):
+			if len(m.ticked) > 0 {
+				m.ticked[m.cursor] = !m.ticked[m.cursor]
+			}
+		case key.Matches(msg, keys.Plan.Confirm):
+			return m, m.confirm()
+		}
+	}
+	return m, nil
+}
+
+// confirm reduces the configured directories to the ticked rows. The
+// directories are filtered rather than the commands passed on, so the
+// launcher re-resolves the same plan the user just confirmed.
+func (m Model) confirm() tea.Cmd {
+	type row struct{ path, profileID string }
+	keep := make(map[row]bool)
+	for i, c := range m.plan.Commands {
+		if m.ticked[i] {
+			keep[row{c.Dir.Path, c.Profile.ID}] = true
+		}
+	}
+	dirs := m.plan.Filter(m.cfg.Directories, func(c launcher.Command) bool {
+		return keep[row{c.Dir.Path, c.Profile.ID}]
+	})
+	dryRun := m.dryRun
+	return func() tea.Msg { return ConfirmMsg{Dirs: dirs, DryRun: dryRun} }
+}
+
+func (m Model) View() string {
+	heading := "Launch plan"
+	if m.dryRun {
+		heading += " (dry run)"
+	}
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(heading)
+
+	var sb strings.Builder
+	sb.WriteString(title + "\n\n")
+
+	if !m.loaded {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  resolving commands…") + "\n")
+		return sb.String()
+	}
+	if len(m.plan.Commands) == 0 {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (nothing to launch — enable directories and assign profiles)") + "\n")
+	}
+
+	for i, c := range m.plan.Commands {
+		check := "[ ]"
+		if m.ticked[i] {
+			check = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("[x]")
+		}
+		cursor := "  "
+		term := c.Terminal
+		if c.GUI() {
+			term = "(gui)"
+		}
+		row := fmt.Sprintf("%-20s %-16s %-14s %s", c.Dir.Name, c.Profile.Label, term, c.Profile.Cmd)
+		if i == m.cursor {
+			cursor = "▸ "
+			row = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Render(row)
+		}
+		sb.WriteString(fmt.Sprintf("%s%s %s\n", cursor, check, row))
+	}
+
+	if m.cursor < len(m.plan.Commands) {
+		c := m.plan.Commands[m.cursor]
+		dim := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+		sb.WriteString("\n")
+		if env := shownEnv(c.Profile); len(env) > 0 {
+			sb.WriteString(dim.Render("  env: "+config.FormatEnv(env)) + "\n")
+		}
+		sb.WriteString(dim.Render("  $ "+launcher.JoinArgv(c.Argv)) + "\n")
+	}
+
+	if len(m.plan.Warnings) > 0 {
+		sb.WriteString("\n")
+		warn := lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+		for _, w := range m.plan.Warnings {
+			sb.WriteString(warn.Render("  ! "+w.String()) + "\n")
+		}
+	}
+
+	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(
+		fmt.Sprintf("\n  %d of %d selected  space toggle  enter launch  esc cancel", m.selectedCount(), len(m.plan.Commands)),
+	)
+	sb.WriteString(help)
+	return sb.String()
+}
+
+func (m Model) selectedCount() int {
+	n := 0
+	for _, t := range m.ticked {
+		if t {
+			n++
+		}
+	}
+	return n
+}
+
+// shownEnv is the environment p is launched with, secrets by reference and
+// .env values hidden.
+func shownEnv(p config.Profile) map[string]string {
+	if len(p.Secrets) == 0 {
+		return p.Env
+	}
+	env := make(map[string]string, len(p.Env)+len(p.Secrets))
+	for k, v := range p.Env {
+		env[k] = v
+	}
+	for k, v := range p.Secrets {
+		env[k] = v
+	}
+	return env
+}
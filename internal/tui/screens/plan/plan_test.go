@@ -0,0 +1,163 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jimbo/gopener/internal/config"
+)
+
+func makeCfg() *config.Config {
+	return &config.Config{
+		Terminal: "xterm",
+		Profiles: []config.Profile{
+			{ID: "p1", Label: "Claude", Cmd: "claude"},
+			{ID: "p2", Label: "Shell", Cmd: "bash"},
+		},
+		Directories: []config.DirConfig{
+			{Path: "/src/api", Name: "api", Enabled: true},
+			{Path: "/src/web", Name: "web", Enabled: true, ProfileIDs: []string{"p1", "gone", "p2"}},
+		},
+	}
+}
+
+// build runs the plan build that Init starts.
+func build(m Model) Model {
+	m, _ = m.Update(m.Init()())
+	return m
+}
+
+func pressKey(m Model, k tea.KeyType) (Model, tea.Cmd) {
+	return m.Update(tea.KeyMsg{Type: k})
+}
+
+func pressRune(m Model, r rune) (Model, tea.Cmd) {
+	return m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+}
+
+func TestRowsAndWarnings(t *testing.T) {
+	m := build(New(makeCfg(), false))
+	if len(m.plan.Commands) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(m.plan.Commands))
+	}
+	for i, ticked := range m.ticked {
+		if !ticked {
+			t.Errorf("row %d should start ticked", i)
+		}
+	}
+
+	v := m.View()
+	for _, want := range []string{"Claude", "Shell", "api: enabled but has no profiles", "profile gone no longer exists"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+}
+
+func TestBuildsInBackground(t *testing.T) {
+	m := New(makeCfg(), false)
+	if v := m.View(); !strings.Contains(v, "resolving commands") {
+		t.Errorf("view should show the plan is loading:\n%s", v)
+	}
+	if _, cmd := pressKey(m, tea.KeyEnter); cmd != nil {
+		t.Error("enter should do nothing before the plan is built")
+	}
+
+	// A plan built for an earlier screen is ignored.
+	stale := New(makeCfg(), false).Init()()
+	m2 := New(makeCfg(), false)
+	if m2, _ = m2.Update(stale); m2.loaded {
+		t.Error("a plan built for another screen should be ignored")
+	}
+	if m2, _ = m2.Update(m2.Init()()); len(m2.plan.Commands) != 2 {
+		t.Errorf("expected 2 rows, got %d", len(m2.plan.Commands))
+	}
+}
+
+func TestUntickAndConfirm(t *testing.T) {
+	c := makeCfg()
+	m := build(New(c, false))
+
+	// Untick the first row (web → Claude) and confirm.
+	m, _ = pressRune(m, ' ')
+	_, cmd := pressKey(m, tea.KeyEnter)
+	if cmd == nil {
+		t.Fatal("expected cmd after enter")
+	}
+	confirm, ok := cmd().(ConfirmMsg)
+	if !ok {
+		t.Fatalf("expected ConfirmMsg, got %T", confirm)
+	}
+	if confirm.DryRun {
+		t.Error("DryRun should be false")
+	}
+
+	var web config.DirConfig
+	for _, d := range confirm.Dirs {
+		if d.Name == "web" {
+			web = d
+		}
+	}
+	if !web.Enabled || len(web.ProfileIDs) != 1 || web.ProfileIDs[0] != "p2" {
+		t.Errorf("confirmed web: got %+v", web)
+	}
+
+	// The saved config is untouched.
+	if len(c.Directories[1].ProfileIDs) != 3 {
+		t.Errorf("config was modified: %+v", c.Directories[1])
+	}
+}
+
+func TestDryRunConfirm(t *testing.T) {
+	m := build(New(makeCfg(), true))
+	_, cmd := pressKey(m, tea.KeyEnter)
+	if confirm := cmd().(ConfirmMsg); !confirm.DryRun {
+		t.Errorf("expected DryRun ConfirmMsg, got %+v", confirm)
+	}
+}
+
+func TestEscGoesBack(t *testing.T) {
+	m := build(New(makeCfg(), false))
+	_, cmd := pressKey(m, tea.KeyEsc)
+	if cmd == nil {
+		t.Fatal("expected cmd after esc")
+	}
+	if _, ok := cmd().(BackMsg); !ok {
+		t.Error("expected BackMsg")
+	}
+}
+
+func TestEnvPreview(t *testing.T) {
+	cfg := makeCfg()
+	cfg.Profiles[0].Env = map[string]string{"NODE_ENV": "dev", "AWS_PROFILE": "personal"}
+	cfg.Directories[1].Env = map[string]string{"AWS_PROFILE": "work"}
+	m := build(New(cfg, false))
+
+	if v := m.View(); !strings.Contains(v, "env: AWS_PROFILE=work NODE_ENV=dev") {
+		t.Errorf("view should show the merged env of the selected row:\n%s", v)
+	}
+	// The directory's env applies to its other profiles too.
+	m, _ = pressKey(m, tea.KeyDown)
+	if v := m.View(); !strings.Contains(v, "env: AWS_PROFILE=work\n") {
+		t.Errorf("view should show the directory env only:\n%s", v)
+	}
+}
+
+func TestDotenvHidden(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	c := makeCfg()
+	web := &c.Directories[1]
+	web.Path = t.TempDir()
+	web.Activate = config.ActivateDotenv
+	if err := os.WriteFile(filepath.Join(web.Path, ".env"), []byte("API_KEY=sk-live-123\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := build(New(c, false)).View()
+	if strings.Contains(v, "sk-live-123") || !strings.Contains(v, "API_KEY="+config.DotenvSecret) {
+		t.Errorf("view should hide the .env value:\n%s", v)
+	}
+}
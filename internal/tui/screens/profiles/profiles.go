@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jimbo/gopener/internal/config"
 	"github.com/jimbo/gopener/internal/keys"
+	"github.com/jimbo/gopener/internal/launcher"
 )
 
 type mode int
@@ -34,10 +35,27 @@ type Model struct {
 	editIdx  int
 	labelIn  textinput.Model
 	cmdIn    textinput.Model
-	focused  int // 0=label, 1=cmd
+	shellIn  textinput.Model
+	titleIn  textinput.Model
+	envIn    textinput.Model
+	onExit   string          // exit policy being edited, one of config.ExitPolicies
+	gui      bool            // kind being edited
+	term     string          // terminal override being edited, "" for the global one
+	wrappers map[string]bool // wrappers being edited, by name
+	wrapIdx  int             // wrapper highlighted in the wrappers field
+	focused  int             // 0=label, 1=cmd, 2=shell, 3=title, 4=env, 5=on exit, 6=kind, 7=terminal, 8=wrappers
 	err      string
 }
 
+// Focus indexes of the selectors, which follow the text inputs.
+const (
+	exitField = 5
+	kindField = 6
+	termField = 7
+	wrapField = 8
+	numFields = 9
+)
+
 func New(cfg *config.Config) Model {
 	label := textinput.New()
 	label.Placeholder = "label"
@@ -49,7 +67,39 @@ func New(cfg *config.Config) Model {
 	cmd.CharLimit = 256
 	cmd.Width = 50
 
-	return Model{cfg: cfg, labelIn: label, cmdIn: cmd}
+	shell := textinput.New()
+	shell.Placeholder = "default shell"
+	shell.CharLimit = 128
+	shell.Width = 30
+
+	title := textinput.New()
+	title.Placeholder = config.DefaultTitle
+	title.CharLimit = 128
+	title.Width = 30
+
+	env := textinput.New()
+	env.Placeholder = "NAME=value ..."
+	env.CharLimit = 512
+	env.Width = 50
+
+	return Model{cfg: cfg, labelIn: label, cmdIn: cmd, shellIn: shell, titleIn: title, envIn: env}
+}
+
+// fields are the form inputs in focus order.
+func (m *Model) fields() []*textinput.Model {
+	return []*textinput.Model{&m.labelIn, &m.cmdIn, &m.shellIn, &m.titleIn, &m.envIn}
+}
+
+// focus moves the cursor to the i-th field.
+func (m *Model) focus(i int) {
+	m.focused = i
+	for j, f := range m.fields() {
+		if j == i {
+			f.Focus()
+		} else {
+			f.Blur()
+		}
+	}
 }
 
 func (m Model) Init() tea.Cmd { return nil }
@@ -82,9 +132,15 @@ func (m Model) updateList(msg tea.Msg) (Model, tea.Cmd) {
 			m.mode = modeAdd
 			m.labelIn.SetValue("")
 			m.cmdIn.SetValue("")
-			m.focused = 0
-			m.labelIn.Focus()
-			m.cmdIn.Blur()
+			m.shellIn.SetValue("")
+			m.titleIn.SetValue("")
+			m.envIn.SetValue("")
+			m.onExit = config.DefaultExitPolicy
+			m.gui = false
+			m.term = ""
+			m.wrappers = make(map[string]bool)
+			m.wrapIdx = 0
+			m.focus(0)
 			m.err = ""
 			return m, textinput.Blink
 		case key.Matches(msg, keys.Profile.Edit):
@@ -96,9 +152,21 @@ func (m Model) updateList(msg tea.Msg) (Model, tea.Cmd) {
 			p := m.cfg.Profiles[m.cursor]
 			m.labelIn.SetValue(p.Label)
 			m.cmdIn.SetValue(p.Cmd)
-			m.focused = 0
-			m.labelIn.Focus()
-			m.cmdIn.Blur()
+			m.shellIn.SetValue("")
+			if p.Shell != nil {
+				m.shellIn.SetValue(p.Shell.String())
+			}
+			m.titleIn.SetValue(p.Title)
+			m.envIn.SetValue(config.FormatEnv(p.Env))
+			m.onExit = p.ExitPolicy()
+			m.gui = p.IsGUI()
+			m.term = p.Terminal
+			m.wrappers = make(map[string]bool)
+			for _, name := range p.Wrappers {
+				m.wrappers[name] = true
+			}
+			m.wrapIdx = 0
+			m.focus(0)
 			m.err = ""
 			return m, textinput.Blink
 		case key.Matches(msg, keys.Profile.Delete):
@@ -123,16 +191,27 @@ func (m Model) updateEdit(msg tea.Msg) (Model, tea.Cmd) {
 		case tea.KeyEsc:
 			m.mode = modeList
 			return m, nil
-		case tea.KeyTab, tea.KeyShiftTab:
-			m.focused = 1 - m.focused
-			if m.focused == 0 {
-				m.labelIn.Focus()
-				m.cmdIn.Blur()
-			} else {
-				m.labelIn.Blur()
-				m.cmdIn.Focus()
-			}
+		case tea.KeyTab:
+			m.focus((m.focused + 1) % numFields)
+			return m, textinput.Blink
+		case tea.KeyShiftTab:
+			m.focus((m.focused + numFields - 1) % numFields)
 			return m, textinput.Blink
+		case tea.KeyLeft, tea.KeyRight, tea.KeySpace:
+			switch m.focused {
+			case exitField:
+				m.onExit = cycleExit(m.onExit, msg.Type == tea.KeyLeft)
+				return m, nil
+			case kindField:
+				m.gui = !m.gui
+				return m, nil
+			case termField:
+				m.term = cycle(m.cfg.TerminalOverrides(), m.term, msg.Type == tea.KeyLeft)
+				return m, nil
+			case wrapField:
+				m.updateWrappers(msg.Type)
+				return m, nil
+			}
 		case tea.KeyEnter:
 			label := strings.TrimSpace(m.labelIn.Value())
 			cmd := strings.TrimSpace(m.cmdIn.Value())
@@ -140,15 +219,55 @@ func (m Model) updateEdit(msg tea.Msg) (Model, tea.Cmd) {
 				m.err = "label and command are required"
 				return m, nil
 			}
+			if err := launcher.CheckCmd(cmd); err != nil {
+				m.err = "command: " + err.Error()
+				return m, nil
+			}
+			sh, err := config.ParseShell(m.shellIn.Value())
+			if err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			var shell *config.Shell
+			if sh.Path != "" {
+				shell = &sh
+			}
+			env, err := config.ParseEnv(m.envIn.Value())
+			if err != nil {
+				m.err = "environment: " + err.Error()
+				return m, nil
+			}
+			title := strings.TrimSpace(m.titleIn.Value())
+			if title == config.DefaultTitle {
+				title = ""
+			}
+			kind := ""
+			if m.gui {
+				kind = config.KindGUI
+			}
 			if m.mode == modeAdd {
 				m.cfg.Profiles = append(m.cfg.Profiles, config.Profile{
-					ID:    newID(),
-					Label: label,
-					Cmd:   cmd,
+					ID:       newID(),
+					Label:    label,
+					Cmd:      cmd,
+					Kind:     kind,
+					Shell:    shell,
+					OnExit:   m.onExit,
+					Terminal: m.term,
+					Title:    title,
+					Env:      env,
+					Wrappers: m.selectedWrappers(),
 				})
 			} else {
 				m.cfg.Profiles[m.editIdx].Label = label
 				m.cfg.Profiles[m.editIdx].Cmd = cmd
+				m.cfg.Profiles[m.editIdx].Kind = kind
+				m.cfg.Profiles[m.editIdx].Shell = shell
+				m.cfg.Profiles[m.editIdx].OnExit = m.onExit
+				m.cfg.Profiles[m.editIdx].Terminal = m.term
+				m.cfg.Profiles[m.editIdx].Title = title
+				m.cfg.Profiles[m.editIdx].Env = env
+				m.cfg.Profiles[m.editIdx].Wrappers = m.selectedWrappers()
 			}
 			m.mode = modeList
 			m.err = ""
@@ -162,9 +281,67 @@ func (m Model) updateEdit(msg tea.Msg) (Model, tea.Cmd) {
 	cmds = append(cmds, c)
 	m.cmdIn, c = m.cmdIn.Update(msg)
 	cmds = append(cmds, c)
+	m.shellIn, c = m.shellIn.Update(msg)
+	cmds = append(cmds, c)
+	m.titleIn, c = m.titleIn.Update(msg)
+	cmds = append(cmds, c)
+	m.envIn, c = m.envIn.Update(msg)
+	cmds = append(cmds, c)
 	return m, tea.Batch(cmds...)
 }
 
+// updateWrappers moves the highlight in the wrappers field with left and
+// right, and toggles the highlighted wrapper with space.
+func (m *Model) updateWrappers(k tea.KeyType) {
+	n := len(m.cfg.Wrappers)
+	if n == 0 {
+		return
+	}
+	switch k {
+	case tea.KeyLeft:
+		m.wrapIdx = (m.wrapIdx + n - 1) % n
+	case tea.KeyRight:
+		m.wrapIdx = (m.wrapIdx + 1) % n
+	case tea.KeySpace:
+		name := m.cfg.Wrappers[m.wrapIdx].Name
+		m.wrappers[name] = !m.wrappers[name]
+	}
+}
+
+// selectedWrappers lists the wrappers ticked in the editor in the order of
+// Config.Wrappers, which is the order they nest in.
+func (m Model) selectedWrappers() []string {
+	var names []string
+	for _, w := range m.cfg.Wrappers {
+		if m.wrappers[w.Name] {
+			names = append(names, w.Name)
+		}
+	}
+	return names
+}
+
+// composed is the command the profile being edited would run, or "" while
+// the form cannot produce one.
+func (m Model) composed() string {
+	sh, err := config.ParseShell(m.shellIn.Value())
+	cmd := strings.TrimSpace(m.cmdIn.Value())
+	if err != nil || cmd == "" {
+		return ""
+	}
+	p := config.Profile{Cmd: cmd, Wrappers: m.selectedWrappers()}
+	if sh.Path != "" {
+		p.Shell = &sh
+	}
+	if m.gui {
+		p.Kind = config.KindGUI
+	}
+	argv, err := launcher.ComposedArgv(m.cfg, p)
+	if err != nil {
+		return ""
+	}
+	return launcher.JoinArgv(argv)
+}
+
 func (m Model) View() string {
 	switch m.mode {
 	case modeList:
@@ -185,19 +362,38 @@ func (m Model) viewList() string {
 	}
 	for i, p := range m.cfg.Profiles {
 		cursor := "  "
-		line := fmt.Sprintf("%s  %-20s  %s", cursor, p.Label, p.Cmd)
+		marker := ">_"
+		if p.IsGUI() {
+			marker = "[]"
+		}
+		line := fmt.Sprintf("%s%s  %-20s  %s", cursor, marker, p.Label, p.Cmd)
 		if i == m.cursor {
 			cursor = "▸ "
-			line = fmt.Sprintf("%s  %-20s  %s", cursor, p.Label, p.Cmd)
+			line = fmt.Sprintf("%s%s  %-20s  %s", cursor, marker, p.Label, p.Cmd)
 			line = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Render(line)
 		} else {
 			line = lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Render(line)
 		}
+		if p.Shell != nil {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (" + p.Shell.String() + ")")
+		}
+		if p.Terminal != "" && !p.IsGUI() {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (in " + p.Terminal + ")")
+		}
+		if len(p.Wrappers) > 0 {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (via " + strings.Join(p.Wrappers, ", ") + ")")
+		}
+		if len(p.Env) > 0 {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (env: " + strings.Join(config.EnvNames(p.Env), ", ") + ")")
+		}
+		if p.ExitPolicy() != config.DefaultExitPolicy && !p.IsGUI() {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (on exit: " + p.ExitPolicy() + ")")
+		}
 		sb.WriteString(line + "\n")
 	}
 
 	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(
-		"\n  a add  e edit  d delete  esc back",
+		"\n  >_ terminal  [] gui app\n  a add  e edit  d delete  esc back",
 	)
 	sb.WriteString(help)
 	return sb.String()
@@ -210,22 +406,100 @@ func (m Model) viewEdit() string {
 	}
 	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(heading)
 
-	labelLabel := "Label:"
-	cmdLabel := "Command:"
-	if m.focused == 0 {
-		labelLabel = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Render("Label:")
-	} else {
-		cmdLabel = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Render("Command:")
-	}
+	labels := []string{"Label:", "Command:", "Shell (e.g. zsh -l -i, blank for default):",
+		"Window title ({name}, {path}, {profile}; blank for default):",
+		"Environment (NAME=value, ${VAR} expands; directories override):", "When the command exits:", "Runs as:", "Terminal:",
+		"Wrappers (outermost first):"}
+	labels[m.focused] = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Render(labels[m.focused])
 
-	parts := []string{title, "", labelLabel, "  " + m.labelIn.View(), "", cmdLabel, "  " + m.cmdIn.View()}
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	kind := "a terminal window"
+	if m.gui {
+		kind = "a GUI app, detached and without a terminal"
+	}
+	parts := []string{title, "", labels[0], "  " + m.labelIn.View(), "", labels[1], "  " + m.cmdIn.View(),
+		hint.Render(`  {{.Name}} {{.Path}} {{.Branch}} {{.SrcDir}} {{env "VAR"}} are quoted; {{raw .Path}} is not`),
+		"", labels[2], "  " + m.shellIn.View(), "", labels[3], "  " + m.titleIn.View(),
+		"", labels[4], "  " + m.envIn.View(),
+		"", labels[5], "  ‹ " + exitDesc(m.onExit) + " ›",
+		"", labels[6], "  ‹ " + kind + " ›",
+		"", labels[7], "  ‹ " + termDesc(m.term) + " ›",
+		"", labels[8], "  " + m.viewWrappers()}
+	if c := m.composed(); c != "" {
+		parts = append(parts, "", lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  $ "+c))
+	}
 	if m.err != "" {
 		parts = append(parts, "", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("  "+m.err))
 	}
-	parts = append(parts, "", lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  tab switch field  enter save  esc cancel"))
+	help := "  tab switch field  enter save  esc cancel"
+	switch {
+	case m.focused == wrapField:
+		help = "  tab switch field  ←/→ move  space toggle  enter save  esc cancel"
+	case m.focused >= exitField:
+		help = "  tab switch field  ←/→ change  enter save  esc cancel"
+	}
+	parts = append(parts, "", lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(help))
 	return strings.Join(parts, "\n")
 }
 
+// viewWrappers shows the configured wrappers with the ticked ones marked.
+func (m Model) viewWrappers() string {
+	if len(m.cfg.Wrappers) == 0 {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(`(none defined; add them under "wrappers" in config.json)`)
+	}
+	items := make([]string, len(m.cfg.Wrappers))
+	for i, w := range m.cfg.Wrappers {
+		check := "[ ]"
+		if m.wrappers[w.Name] {
+			check = "[x]"
+		}
+		items[i] = check + " " + w.Name
+		if m.focused == wrapField && i == m.wrapIdx {
+			items[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Render(items[i])
+		}
+	}
+	return strings.Join(items, "  ")
+}
+
+// cycleExit returns the exit policy after (or, with back, before) policy.
+func cycleExit(policy string, back bool) string {
+	return cycle(config.ExitPolicies, policy, back)
+}
+
+// cycle returns the choice after (or, with back, before) current, wrapping
+// around. A current value that is not a choice moves to the first one.
+func cycle(choices []string, current string, back bool) string {
+	n := len(choices)
+	for i, c := range choices {
+		if c == current {
+			if back {
+				return choices[(i+n-1)%n]
+			}
+			return choices[(i+1)%n]
+		}
+	}
+	return choices[0]
+}
+
+// termDesc describes a terminal override in the editor.
+func termDesc(term string) string {
+	if term == "" {
+		return "default (from settings)"
+	}
+	return term
+}
+
+// exitDesc describes an exit policy in the editor.
+func exitDesc(policy string) string {
+	switch policy {
+	case config.ExitHold:
+		return "hold: show the exit status until a key is pressed"
+	case config.ExitShell:
+		return "shell: drop into a shell in the directory"
+	}
+	return "close the window"
+}
+
 // newID generates a short random ID without external dependencies.
 func newID() string {
 	b := make([]byte, 8)
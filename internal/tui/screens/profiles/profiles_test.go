@@ -2,6 +2,8 @@ package profiles
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -34,6 +36,18 @@ func pressRune(m Model, r rune) (Model, tea.Cmd) {
 	return m.Update(msg)
 }
 
+// focusField presses shift+tab, which wraps around, until field i has focus.
+func focusField(t *testing.T, m Model, i int) Model {
+	t.Helper()
+	for n := 0; m.focused != i; n++ {
+		if n == numFields {
+			t.Fatalf("field %d never got focus", i)
+		}
+		m, _ = pressKey(m, tea.KeyShiftTab)
+	}
+	return m
+}
+
 func TestNavigation(t *testing.T) {
 	m := New(cfg())
 
@@ -125,3 +139,233 @@ func TestViewRendersWithoutPanic(t *testing.T) {
 		t.Error("expected non-empty view")
 	}
 }
+
+func TestEditShell(t *testing.T) {
+	c := cfg()
+	m := New(c)
+
+	m, _ = pressRune(m, 'e')
+	m = focusField(t, m, 2)
+	for _, r := range "fish -x" {
+		m, _ = pressRune(m, r)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.mode != modeEdit || m.err == "" {
+		t.Fatalf("expected an error for a bad option, got mode %v err %q", m.mode, m.err)
+	}
+
+	m.shellIn.SetValue("fish -l")
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.mode != modeList {
+		t.Fatalf("expected modeList after save, got %v (err %q)", m.mode, m.err)
+	}
+	if sh := c.Profiles[0].Shell; sh == nil || *sh != (config.Shell{Path: "fish", Login: true}) {
+		t.Errorf("shell: got %+v", sh)
+	}
+
+	// Clearing the field goes back to the global shell.
+	m, _ = pressRune(m, 'e')
+	if m.shellIn.Value() != "fish -l" {
+		t.Errorf("shell input: got %q", m.shellIn.Value())
+	}
+	m.shellIn.SetValue("")
+	m, _ = pressKey(m, tea.KeyEnter)
+	if c.Profiles[0].Shell != nil {
+		t.Errorf("shell should be cleared, got %+v", c.Profiles[0].Shell)
+	}
+}
+
+func TestEditCmdTemplate(t *testing.T) {
+	c := cfg()
+	m := New(c)
+
+	m, _ = pressRune(m, 'e')
+	m.cmdIn.SetValue("code {{.Pth}}")
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.mode != modeEdit || m.err == "" {
+		t.Fatalf("expected an error for an unknown placeholder, got mode %v err %q", m.mode, m.err)
+	}
+
+	m.cmdIn.SetValue("code {{.Path}}")
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.mode != modeList {
+		t.Fatalf("expected modeList after save, got %v (err %q)", m.mode, m.err)
+	}
+	if got := c.Profiles[0].Cmd; got != "code {{.Path}}" {
+		t.Errorf("cmd: got %q", got)
+	}
+}
+
+func TestEditTitle(t *testing.T) {
+	c := cfg()
+	m := New(c)
+
+	m, _ = pressRune(m, 'e')
+	m = focusField(t, m, 3)
+	for _, r := range "{profile} @ {name}" {
+		m, _ = pressRune(m, r)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if got := c.Profiles[0].Title; got != "{profile} @ {name}" {
+		t.Errorf("title: got %q", got)
+	}
+
+	// Clearing the field, or typing the default, stores no template.
+	for _, title := range []string{"", config.DefaultTitle} {
+		m, _ = pressRune(m, 'e')
+		m.titleIn.SetValue(title)
+		m, _ = pressKey(m, tea.KeyEnter)
+		if got := c.Profiles[0].Title; got != "" {
+			t.Errorf("title %q: stored %q", title, got)
+		}
+	}
+}
+
+func TestEditEnv(t *testing.T) {
+	c := cfg()
+	m := New(c)
+
+	m, _ = pressRune(m, 'e')
+	m = focusField(t, m, 4)
+	for _, r := range "1X=y" {
+		m, _ = pressRune(m, r)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if m.mode != modeEdit || m.err == "" {
+		t.Fatalf("expected an error for a bad name, got mode %v err %q", m.mode, m.err)
+	}
+
+	m.envIn.SetValue(`NODE_ENV=dev GREETING="hi there"`)
+	m, _ = pressKey(m, tea.KeyEnter)
+	want := map[string]string{"NODE_ENV": "dev", "GREETING": "hi there"}
+	if got := c.Profiles[0].Env; !reflect.DeepEqual(got, want) {
+		t.Errorf("env: got %v", got)
+	}
+	if v := m.View(); !strings.Contains(v, "(env: GREETING, NODE_ENV)") {
+		t.Errorf("list should name the variables:\n%s", v)
+	}
+
+	m, _ = pressRune(m, 'e')
+	if got := m.envIn.Value(); got != `GREETING="hi there" NODE_ENV=dev` {
+		t.Errorf("env input: got %q", got)
+	}
+}
+
+func TestEditWrappers(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	c := cfg()
+	c.Wrappers = []config.Wrapper{
+		{Name: "nice", Argv: []string{"nice", "-n", "10"}},
+		{Name: "jail", Argv: []string{"firejail", "--whitelist={dir}"}},
+	}
+	m := New(c)
+
+	m, _ = pressRune(m, 'e')
+	m = focusField(t, m, wrapField)
+	// Tick jail, then nice: they still nest in the configured order.
+	m, _ = pressKey(m, tea.KeyRight)
+	m, _ = pressKey(m, tea.KeySpace)
+	m, _ = pressKey(m, tea.KeyRight)
+	m, _ = pressKey(m, tea.KeySpace)
+	want := "$ nice -n 10 firejail '--whitelist={dir}' /bin/sh -c 'claude --continue'"
+	if v := m.View(); !strings.Contains(v, want) {
+		t.Errorf("editor should show the composed command %q:\n%s", want, v)
+	}
+
+	m, _ = pressKey(m, tea.KeyEnter)
+	if got := c.Profiles[0].Wrappers; !reflect.DeepEqual(got, []string{"nice", "jail"}) {
+		t.Errorf("wrappers: got %q", got)
+	}
+	if v := m.View(); !strings.Contains(v, "(via nice, jail)") {
+		t.Errorf("list should name the wrappers:\n%s", v)
+	}
+
+	// Editing again starts from the saved ticks.
+	m, _ = pressRune(m, 'e')
+	m = focusField(t, m, wrapField)
+	m, _ = pressKey(m, tea.KeySpace)
+	m, _ = pressKey(m, tea.KeyEnter)
+	if got := c.Profiles[0].Wrappers; !reflect.DeepEqual(got, []string{"jail"}) {
+		t.Errorf("after unticking nice: got %q", got)
+	}
+}
+
+func TestEditExitPolicy(t *testing.T) {
+	c := cfg()
+	m := New(c)
+
+	m, _ = pressRune(m, 'e')
+	if m.onExit != config.ExitClose {
+		t.Fatalf("initial policy: got %q, want close", m.onExit)
+	}
+	m = focusField(t, m, exitField)
+	m, _ = pressKey(m, tea.KeyRight)
+	if m.onExit != config.ExitHold {
+		t.Errorf("after right: got %q, want hold", m.onExit)
+	}
+	m, _ = pressKey(m, tea.KeyLeft)
+	m, _ = pressKey(m, tea.KeyLeft)
+	if m.onExit != config.ExitShell {
+		t.Errorf("after two lefts: got %q, want shell (wrapping)", m.onExit)
+	}
+	if m.labelIn.Value() != "Claude" {
+		t.Errorf("label changed while cycling: %q", m.labelIn.Value())
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if c.Profiles[0].OnExit != config.ExitShell {
+		t.Errorf("saved policy: got %q, want shell", c.Profiles[0].OnExit)
+	}
+
+	// close is stored explicitly, as the default differs by platform.
+	m, _ = pressRune(m, 'e')
+	m.onExit = config.ExitClose
+	m, _ = pressKey(m, tea.KeyEnter)
+	if c.Profiles[0].OnExit != config.ExitClose {
+		t.Errorf("saved policy: got %q, want close", c.Profiles[0].OnExit)
+	}
+}
+
+func TestEditKind(t *testing.T) {
+	c := cfg()
+	m := New(c)
+	m.cursor = 1
+
+	m, _ = pressRune(m, 'e')
+	m = focusField(t, m, kindField)
+	m, _ = pressKey(m, tea.KeySpace)
+	m, _ = pressKey(m, tea.KeyEnter)
+	if !c.Profiles[1].IsGUI() {
+		t.Fatalf("kind: got %q, want gui", c.Profiles[1].Kind)
+	}
+	if v := m.View(); !strings.Contains(v, "[]  Code") || !strings.Contains(v, ">_  Claude") {
+		t.Errorf("list should mark both kinds:\n%s", v)
+	}
+
+	m, _ = pressRune(m, 'e')
+	if !m.gui {
+		t.Error("editing a GUI profile should start with the GUI kind")
+	}
+}
+
+func TestEditTerminal(t *testing.T) {
+	c := cfg()
+	c.Terminals = []config.CustomTerminal{{Name: "foot", Binary: "foot", Argv: []string{"-e", "{cmd}"}}}
+	m := New(c)
+
+	m, _ = pressRune(m, 'e')
+	m = focusField(t, m, termField)
+	if m.term != "" {
+		t.Fatalf("initial terminal: got %q, want default", m.term)
+	}
+	m, _ = pressKey(m, tea.KeyLeft) // wraps to the last choice
+	if m.term != "foot" {
+		t.Fatalf("after left: got %q, want foot", m.term)
+	}
+	m, _ = pressKey(m, tea.KeyEnter)
+	if c.Profiles[0].Terminal != "foot" {
+		t.Errorf("saved terminal: got %q", c.Profiles[0].Terminal)
+	}
+	if v := m.View(); !strings.Contains(v, "(in foot)") {
+		t.Errorf("list should show the override:\n%s", v)
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jimbo/gopener/internal/config"
 	"github.com/jimbo/gopener/internal/keys"
+	"github.com/jimbo/gopener/internal/launcher"
 )
 
 // GoBackMsg signals to return to the main screen.
@@ -19,18 +21,29 @@ type Model struct {
 	cursor           int
 	availableTerms   []string
 	statusMsg        string
+	editingShell     bool
+	shellIn          textinput.Model
 }
 
 func New(cfg *config.Config) Model {
+	shell := textinput.New()
+	shell.Placeholder = config.DefaultShell()
+	shell.CharLimit = 128
+	shell.Width = 30
+
 	return Model{
 		cfg:            cfg,
-		availableTerms: config.AvailableTerminals(),
+		availableTerms: cfg.TerminalChoices(),
+		shellIn:        shell,
 	}
 }
 
 func (m Model) Init() tea.Cmd { return nil }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if m.editingShell {
+		return m.updateShell(msg)
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
@@ -53,11 +66,108 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 					m.statusMsg = fmt.Sprintf("Terminal set to %s", m.cfg.Terminal)
 				}
 			}
+		case key.Matches(msg, keys.Settings.TmuxLayout):
+			if m.cfg.Tmux.Layout == config.TmuxLayoutWindow {
+				m.cfg.Tmux.Layout = config.TmuxLayoutSession
+			} else {
+				m.cfg.Tmux.Layout = config.TmuxLayoutWindow
+			}
+			if err := m.cfg.Save(); err != nil {
+				m.statusMsg = fmt.Sprintf("error saving: %v", err)
+			} else {
+				m.statusMsg = fmt.Sprintf("tmux layout set to %s", tmuxLayoutDesc(m.cfg.Tmux))
+			}
+		case key.Matches(msg, keys.Settings.Shell):
+			m.editingShell = true
+			m.shellIn.SetValue(m.cfg.Shell.String())
+			m.shellIn.Focus()
+			m.statusMsg = ""
+			return m, textinput.Blink
+		case key.Matches(msg, keys.Settings.Group):
+			m.cfg.Group = !m.cfg.Group
+			if err := m.cfg.Save(); err != nil {
+				m.statusMsg = fmt.Sprintf("error saving: %v", err)
+			} else {
+				m.statusMsg = fmt.Sprintf("Profiles open as %s", groupDesc(m.cfg))
+			}
 		}
 	}
 	return m, nil
 }
 
+// updateShell edits the global shell, e.g. "zsh -l -i".
+func (m Model) updateShell(msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.editingShell = false
+			m.shellIn.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			sh, err := config.ParseShell(m.shellIn.Value())
+			if err != nil {
+				m.statusMsg = err.Error()
+				return m, nil
+			}
+			m.cfg.Shell = sh
+			m.editingShell = false
+			m.shellIn.Blur()
+			if err := m.cfg.Save(); err != nil {
+				m.statusMsg = fmt.Sprintf("error saving: %v", err)
+			} else {
+				m.statusMsg = fmt.Sprintf("Shell set to %s", shellDesc(m.cfg.Shell))
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.shellIn, cmd = m.shellIn.Update(msg)
+	return m, cmd
+}
+
+// shellDesc describes the global shell, naming $SHELL when it is unset.
+func shellDesc(sh config.Shell) string {
+	if sh.Path == "" {
+		sh.Path = config.DefaultShell()
+		return sh.String() + " ($SHELL)"
+	}
+	return sh.String()
+}
+
+// groupDesc describes how a directory's profiles open with the current
+// terminal and group setting.
+func groupDesc(cfg *config.Config) string {
+	switch {
+	case !cfg.Group:
+		return "separate windows"
+	case config.IsMultiplexer(cfg.Terminal):
+		return "tabs of one window (ignored by " + cfg.Terminal + ")"
+	case cfg.Terminal != "" && !launcher.CanGroup(cfg, cfg.Terminal):
+		return "tabs of one window (unsupported by " + cfg.Terminal + ", separate windows)"
+	}
+	return "tabs of one window"
+}
+
+// zellijSessionDesc names the zellij session launches go to.
+func zellijSessionDesc(z config.ZellijConfig) string {
+	if z.Session != "" {
+		return z.Session
+	}
+	return "gopener"
+}
+
+// tmuxLayoutDesc describes the configured tmux layout in a few words.
+func tmuxLayoutDesc(t config.TmuxConfig) string {
+	if t.Layout == config.TmuxLayoutWindow {
+		session := t.Session
+		if session == "" {
+			session = "gopener"
+		}
+		return fmt.Sprintf("one window per directory in session %q", session)
+	}
+	return "one session per directory"
+}
+
 func (m Model) View() string {
 	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Settings")
 	subtitle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("Terminal Emulator")
@@ -97,17 +207,37 @@ func (m Model) View() string {
 			termStr = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Render(term)
 		}
 
+		if m.cfg.FindTerminal(term) != nil {
+			termStr += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" (custom)")
+		}
+
 		line := fmt.Sprintf("%s%s %s", cursor, check, termStr)
 		sb.WriteString(line + "\n")
 	}
 
+	if m.cfg.Terminal == "tmux" {
+		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  tmux layout: "+tmuxLayoutDesc(m.cfg.Tmux)) + "\n")
+	}
+	if m.cfg.Terminal == "zellij" {
+		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  zellij session: "+zellijSessionDesc(m.cfg.Zellij)) + "\n")
+	}
+
+	sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  profiles open as: "+groupDesc(m.cfg)) + "\n")
+	if m.editingShell {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Render("  shell (e.g. zsh -l -i): ") + m.shellIn.View() + "\n")
+	} else {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  shell: "+shellDesc(m.cfg.Shell)) + "\n")
+	}
+
 	if m.statusMsg != "" {
 		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Render(m.statusMsg) + "\n")
 	}
 
-	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(
-		"\n  enter select  esc back",
-	)
+	helpText := "\n  enter select  l tmux layout  g group as tabs  s shell  esc back"
+	if m.editingShell {
+		helpText = "\n  enter save  esc cancel"
+	}
+	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(helpText)
 	sb.WriteString(help)
 
 	return sb.String()